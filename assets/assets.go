@@ -0,0 +1,55 @@
+// Package assets embeds the data files the simulation needs, so it runs the
+// same from any working directory: the game, the headless runner and tests.
+// Sprites, backgrounds and maps are still read from disk by whoever draws
+// or loads them, from under Dir.
+package assets
+
+import (
+	_ "embed"
+	"os"
+	"path/filepath"
+)
+
+// FrameData is framedata.json: hitboxes, hurtboxes, events and combat
+// numbers per character and animation
+//
+//go:embed framedata.json
+var FrameData []byte
+
+// Combos is combos.json: the player's attack chains
+//
+//go:embed combos.json
+var Combos []byte
+
+// Dir is where the on-disk assets live. It defaults to the first assets
+// directory found walking up from the working directory, so the game runs from
+// the repo root, from cmd or from a package under test. Set it (eg: from a
+// -assets flag) when the assets live somewhere else.
+var Dir = findDir()
+
+// findDir walks up from the working directory looking for assets/framedata.json
+func findDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "assets"
+	}
+	for {
+		candidate := filepath.Join(dir, "assets")
+		if _, err := os.Stat(filepath.Join(candidate, "framedata.json")); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "assets"
+		}
+		dir = parent
+	}
+}
+
+// Path resolves an asset name against Dir. Absolute paths are kept as they are.
+func Path(name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(Dir, name)
+}
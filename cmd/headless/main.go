@@ -0,0 +1,105 @@
+// headless steps the game world without a window, eg: on a CI box with no display
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"player/assets"
+	"player/internal/core"
+	"player/internal/game"
+)
+
+func main() {
+	levelPath := flag.String("level", game.FirstLevel().ID, "registered level ID or path to a level map")
+	ticks := flag.Int("ticks", core.TickRate*10, "number of simulation steps to run")
+	move := flag.Int("move", 0, "horizontal input held for the whole run (-1 left, 0 none, 1 right)")
+	every := flag.Int("every", core.TickRate, "print the player state every n ticks (0 to only print the end)")
+	recordPath := flag.String("record", "", "record the run to a replay file")
+	replayPath := flag.String("replay", "", "play back a replay file and check it reproduces")
+	loadSlot := flag.Int("load", -1, "start from a save slot instead of a fresh level")
+	saveSlot := flag.Int("save", -1, "save the world to a slot after the run")
+	flag.StringVar(&assets.Dir, "assets", assets.Dir, "directory the level maps live in")
+	flag.StringVar(&game.SaveDir, "saves", game.SaveDir, "directory the save slots live in")
+	flag.Parse()
+
+	if *replayPath != "" {
+		replay(*replayPath)
+		return
+	}
+
+	world, err := game.LoadHeadlessWorld(*levelPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer world.Close()
+
+	if *loadSlot >= 0 {
+		if *recordPath != "" {
+			log.Fatal("-load can not be combined with -record, replays start from a fresh level")
+		}
+		if err := world.LoadGame(*loadSlot); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("loaded slot", *loadSlot, "level", world.LevelID)
+	}
+
+	if *recordPath != "" {
+		world.Recorder = game.NewRecorder(world)
+	}
+
+	input := core.InputState{Direction: core.Direction{LeftRight: int8(*move)}}
+	for i := 0; i < *ticks; i++ {
+		world.Step(&input)
+		if changed, err := world.AdvanceLevel(); err != nil {
+			log.Fatal(err)
+		} else if changed {
+			fmt.Println("entered level", world.LevelID)
+		}
+		if *every > 0 && world.Tick%uint64(*every) == 0 {
+			printPlayer(world)
+		}
+	}
+	printPlayer(world)
+
+	if *saveSlot >= 0 {
+		if err := world.SaveGame(*saveSlot); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("saved slot", *saveSlot)
+	}
+
+	if world.Recorder != nil {
+		if err := game.SaveRecording(*recordPath, world.Recorder.Finish(world)); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// replay plays a recording back on the level it was recorded on
+func replay(path string) {
+	rec, err := game.LoadRecording(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	world, err := game.LoadHeadlessWorld(rec.LevelID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer world.Close()
+
+	err = world.Replay(rec)
+	printPlayer(world)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("replay reproduced")
+}
+
+func printPlayer(world *game.World) {
+	p := world.Player
+	fmt.Printf("tick=%d x=%.2f y=%.2f velX=%.2f velY=%.2f state=%d health=%.0f power=%.0f combo=%d score=%d checkpoint=%s\n",
+		world.Tick, p.Pos.X, p.Pos.Y, p.Physics.VelX, p.Physics.VelY, p.State.GetPlayerState(), p.Combat.Health, p.Combat.Power, p.Combo.Hits, world.Score, p.CheckpointID)
+}
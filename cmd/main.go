@@ -1,19 +1,25 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 
+	"player/assets"
 	"player/enemy"
 	"player/internal/core"
+	"player/internal/game"
+	"player/internal/render"
 	"player/internal/system"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const (
-	// playerSpriteSheetPath = "../assets/NewGideonGraves.png"
-	playerSpriteSheetPath = "../assets/GideonGraves.png"
+	// playerSpriteSheetPath = "NewGideonGraves.png"
+	playerSpriteSheetPath = "GideonGraves.png" // under assets.Dir
+	enemySpriteSheetPath  = "GideonGraves.png"
 	screenWidth           = 1360
 	screenHeight          = 768
 	// screenWidth  = 1920
@@ -21,7 +27,7 @@ const (
 )
 
 type Game struct {
-	state core.GameState
+	sm    *game.StateMachine // title menu, playing, pause and game over scenes
 	input core.InputState
 
 	// ------ Entities ------
@@ -29,74 +35,85 @@ type Game struct {
 
 	ParallelEnemyManager *enemy.ParallelEnemyManager // enemy manager
 
-	Background      *ebiten.Image
-	LevelData       *ebiten.Image
-	Tileset         *ebiten.Image
-	Level           []core.Platform
-	DynamicQuadtree *core.DynamicQuadtree
+	// ------ Simulation ------
+	world *game.World // platforms, quadtree, player and enemies of the loaded level
+
+	// ------ Images ------
+	PlayerSheet *ebiten.Image
+	EnemySheet  *ebiten.Image
+	Background  *ebiten.Image
+	Tileset     *ebiten.Image
+
+	session *game.Session // the world plus the replay and retry state the scenes share
+
+	// ------ Replays ------
+	recordPath string // where to write the recording when the game closes
 
 	// Meta Data
-	score     int
-	tickCount int
-	isDebug   bool
+	isDebug bool
 }
 
-var doOnce = false
-
-func (g *Game) loadLevel() {
-	if !doOnce {
-		core.WorldInit()
-		g.Level = g.player.LoadLevel(g.LevelData)
-		for i := range g.Level {
-			if g.Level[i].TileInfo.TileType == core.EnemyBasic {
-				// we are now registering the enemy basic tile to the quadtree
-				continue
-			}
-			g.DynamicQuadtree.Insert(&g.Level[i])
-		}
-		g.ParallelEnemyManager.AddEnemyToLevel(g.Level)
-		fmt.Println("Level loaded")
-		doOnce = true
+// enterLevel loads a registered level into the world, with its background
+func (g *Game) enterLevel(info game.LevelInfo) error {
+	if err := g.world.EnterLevel(info); err != nil {
+		return err
+	}
+	g.loadBackground()
+	fmt.Println("Level loaded:", info.ID)
+	return nil
+}
+
+// loadBackground loads the background of the level the world is in
+func (g *Game) loadBackground() {
+	info, err := game.LevelByID(g.world.LevelID)
+	if err != nil {
+		return
+	}
+	if g.Background, err = render.LoadImage(assets.Path(info.Background)); err != nil {
+		log.Fatal(err)
 	}
 }
 
-// run 60 TPS
+// run at ebiten.TPS()
 // run automatically every frame
 func (g *Game) Update() error {
-
-	g.loadLevel()
-
-	// Update enemies
-	g.ParallelEnemyManager.Update(g.player, g.DynamicQuadtree)
-
 	// poll input -> call another function to handle input
-	system.HandleInput(&g.input)
-
-	core.UpdatePlayer(g.player, &g.input, g.DynamicQuadtree)
-
-	g.player.UpdateAnimation()
-
-	// update camera position
-	g.player.UpdateCamera(float64(screenWidth), float64(screenHeight), float64(core.Level_1_Width), float64(core.Level_1_Height))
+	if !g.session.Replaying() {
+		system.HandleInput(&g.input)
+	}
 
-	// render game state
+	// only the top scene updates, the playing scene steps the world
+	tps := float64(ebiten.TPS())
+	if tps <= 0 {
+		tps = core.TickRate
+	}
+	if err := g.sm.Update(&g.input, 1.0/tps); err != nil {
+		if errors.Is(err, game.ErrQuit) {
+			return ebiten.Termination
+		}
+		return err
+	}
 	return nil
 }
 
+// saveRecording writes the input recording, if one was requested
+func (g *Game) saveRecording() {
+	if g.world == nil || g.world.Recorder == nil {
+		return
+	}
+	if err := game.SaveRecording(g.recordPath, g.world.Recorder.Finish(g.world)); err != nil {
+		log.Println("save recording:", err)
+		return
+	}
+	fmt.Println("Recording saved to", g.recordPath)
+}
+
 // run automatically every frame
 func (g *Game) Draw(screen *ebiten.Image) {
-	// draw background
-	g.player.DrawParallaxBackground(screen, g.Background, float64(screenWidth), float64(screenHeight))
-
-	// draw level
-	g.player.DrawLevel(screen, g.DynamicQuadtree, float64(screenWidth), float64(screenHeight), g.Tileset)
-
-	// draw player animation
-	g.player.DrawPlayerAnimation(screen)
-	// draw UI
-
-	// draw enemies
-	g.ParallelEnemyManager.DrawEnemies(screen, g.player.Camera)
+	if g.world == nil {
+		return
+	}
+	g.drawScenes(screen)
 }
 
 // run automatically every frame
@@ -107,34 +124,89 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeigh
 
 // run Once
 func main() {
-	backGroundData := core.LoadImage(core.Background_1)
-	levelData := core.LoadImage(core.Level_1)
-	tileData := core.LoadImage(core.Tileset)
-	img := core.LoadImage(playerSpriteSheetPath)
-
-	game := &Game{
-		state: core.ModeMenu,
-		player: func() *core.PlayerRuntime {
-			p := core.InitPlayer(img)
-			return &p
-		}(),
+	recordPath := flag.String("record", "", "record the inputs of this run to a replay file")
+	replayPath := flag.String("replay", "", "play back a replay file instead of reading the keyboard")
+	levelID := flag.String("level", game.FirstLevel().ID, "level to start on")
+	loadSlot := flag.Int("load", -1, "start from a save slot instead of a fresh level")
+	flag.StringVar(&assets.Dir, "assets", assets.Dir, "directory the sprites, backgrounds and level maps live in")
+	flag.StringVar(&game.SaveDir, "saves", game.SaveDir, "directory the save slots live in")
+	flag.Parse()
+
+	player, err := core.InitPlayer()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &Game{
+		player: &player,
 
 		ParallelEnemyManager: nil,
 
-		Background:      backGroundData,
-		LevelData:       levelData,
-		Tileset:         tileData,
-		score:           0,
-		tickCount:       0,
-		isDebug:         false,
-		Level:           []core.Platform{},
-		DynamicQuadtree: core.NewDynamicQuadtree(core.AABB{X: 0, Y: 0, Width: float64(core.Level_1_Width), Height: float64(core.Level_1_Height)}),
+		isDebug:    false,
+		recordPath: *recordPath,
+	}
+	if g.Tileset, err = render.LoadImage(assets.Path(core.Tileset)); err != nil {
+		log.Fatal(err)
+	}
+	if g.PlayerSheet, err = render.LoadImage(assets.Path(playerSpriteSheetPath)); err != nil {
+		log.Fatal(err)
+	}
+	if g.EnemySheet, err = render.LoadImage(assets.Path(enemySpriteSheetPath)); err != nil {
+		log.Fatal(err)
+	}
+
+	// the quadtree is created with the world when the level loads
+	g.ParallelEnemyManager, err = enemy.DefaultParallelConfig(g.player, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Parallel Enemy Manager will create ", g.ParallelEnemyManager.WorkerCount, "workers")
+
+	if g.world, err = game.NewWorld(g.player, g.ParallelEnemyManager); err != nil {
+		log.Fatal(err)
+	}
+	g.world.ViewWidth = float64(screenWidth)
+	g.world.ViewHeight = float64(screenHeight)
+
+	g.session = game.NewSession(g.world)
+	g.session.OnLevelLoaded = g.loadBackground
+	g.session.OnMessage = func(msg string) { fmt.Println(msg) }
+
+	var recording *game.Recording
+	if *replayPath != "" {
+		if recording, err = game.LoadRecording(*replayPath); err != nil {
+			log.Fatal(err)
+		}
+		*levelID = recording.LevelID
+	}
+
+	info, err := game.LevelByID(*levelID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := g.enterLevel(info); err != nil {
+		log.Fatal(err)
 	}
 
-	var parallelEnemyManager = enemy.DefaultParallelConfig(game.player, game.DynamicQuadtree)
-	fmt.Println("Parallel Enemy Manager will create ", parallelEnemyManager.WorkerCount, "workers")
+	g.sm = game.NewStateMachine(game.NewMenuScene(g.session))
+	if *loadSlot >= 0 {
+		if recording != nil || g.recordPath != "" {
+			log.Fatal("-load can not be combined with -record or -replay, replays start from a fresh level")
+		}
+		if err := g.session.LoadGame(*loadSlot); err != nil {
+			log.Fatal(err)
+		}
+		// straight into the saved game
+		g.sm.Reset(game.NewPlayingScene(g.session))
+	}
+	if recording != nil {
+		g.session.Replay(recording)
 
-	game.ParallelEnemyManager = &parallelEnemyManager
+		// a replay starts on the level it was recorded on, skip the title
+		g.sm.Reset(game.NewPlayingScene(g.session))
+	} else if g.recordPath != "" {
+		g.world.Recorder = game.NewRecorder(g.world)
+	}
 
 	// ebiten.SetWindowSize(640, 480) // 640, 480
 	ebiten.SetWindowSize(screenWidth, screenHeight)
@@ -142,10 +214,11 @@ func main() {
 
 	// need to setup the brain for later
 
-	if err := ebiten.RunGame(game); err != nil {
-		if game.ParallelEnemyManager != nil {
-			game.ParallelEnemyManager.Shutdown()
+	if err := ebiten.RunGame(g); err != nil {
+		if g.ParallelEnemyManager != nil {
+			g.ParallelEnemyManager.Shutdown()
 		}
 		log.Fatal(err)
 	}
+	g.saveRecording()
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/color"
+
+	"player/internal/game"
+	"player/internal/render"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// drawScenes draws every scene of the stack, bottom first, so an overlay like
+// the pause menu shows the game behind it
+func (g *Game) drawScenes(screen *ebiten.Image) {
+	for _, s := range g.sm.Scenes() {
+		switch s := s.(type) {
+		case *game.MenuScene:
+			drawMenu(screen, "Pirate Adventure", s.Menu)
+		case *game.PlayingScene:
+			g.drawPlaying(screen)
+		case *game.PauseScene:
+			dimScreen(screen)
+			drawMenu(screen, "Paused", s.Menu)
+		case *game.GameOverScene:
+			dimScreen(screen)
+			drawMenu(screen, "Game Over", s.Menu)
+		}
+	}
+}
+
+// drawMenu prints a title and the options of m, the selected one marked with >
+func drawMenu(screen *ebiten.Image, title string, m *game.Menu) {
+	x, y := screenWidth/2-60, screenHeight/2-40
+	ebitenutil.DebugPrintAt(screen, title, x, y)
+	for i, option := range m.Options {
+		prefix := "  "
+		if i == m.Selected {
+			prefix = "> "
+		}
+		ebitenutil.DebugPrintAt(screen, prefix+option, x, y+30+i*16)
+	}
+}
+
+// dimScreen darkens whatever was drawn below an overlay
+func dimScreen(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy()), color.RGBA{0, 0, 0, 160}, false)
+}
+
+// ---------------- playing ----------------
+
+// drawPlaying draws the level, the player, the enemies and the projectiles
+func (g *Game) drawPlaying(screen *ebiten.Image) {
+	// how far we are between the last two simulation steps
+	alpha := g.world.Clock.Alpha
+
+	camera := g.player.ViewCamera(alpha)
+
+	// draw background
+	render.DrawParallaxBackground(screen, g.Background, camera, float64(screenWidth), float64(screenHeight), g.world.Width)
+
+	// draw level
+	render.DrawLevel(screen, g.Tileset, g.world.Quadtree, camera, float64(screenWidth), float64(screenHeight), alpha)
+
+	// draw player animation
+	render.DrawPlayer(screen, g.PlayerSheet, g.player, alpha)
+	// draw UI
+
+	// draw enemies
+	render.DrawEnemies(screen, g.EnemySheet, g.ParallelEnemyManager, camera, alpha)
+
+	// draw projectiles
+	render.DrawProjectiles(screen, g.world.Projectiles, camera, alpha)
+}
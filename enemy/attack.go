@@ -0,0 +1,156 @@
+package enemy
+
+import (
+	"math"
+
+	"player/internal/core"
+)
+
+// ------------------------ attack constants ------------------------
+const (
+	HurtStun     = 0.4 // seconds a hit enemy can't act
+	ParryStagger = 1.2 // seconds an enemy whose swing was parried can't act
+)
+
+// AttackHitboxes are the areas the enemy's attack hurts this step, from the
+// frame data of its attack animation. They are only there during the active
+// frames of the swing, and until the swing lands.
+func (e *EnemyRuntime) AttackHitboxes() []core.AABB {
+	if !e.State.IsEnemyAttacking() || e.AttackLanded {
+		return nil
+	}
+	return e.Frame.HitboxesOn(e.GetBounds(), e.FlipX)
+}
+
+// AttackHit is the hit the enemy's attack deals, damage scaled by its
+// strength. Attacks without damage in the frame data deal DefaultAttackDamage.
+func (e *EnemyRuntime) AttackHit() core.Hit {
+	hit := core.NewHit(DefaultAttackDamage, e.Pos.X+e.Width/2)
+	if e.FrameData != nil && e.FrameData.Damage > 0 {
+		hit = e.FrameData.Hit(hit.SourceX)
+	}
+	hit.Damage *= e.Strength / 100
+	return hit
+}
+
+// HitPlayer lands the current swing on the player if a hitbox overlaps their
+// hurtboxes, every swing hits at most once. It returns the damage dealt.
+func (e *EnemyRuntime) HitPlayer(player *core.PlayerRuntime) float64 {
+	boxes := e.AttackHitboxes()
+	if len(boxes) == 0 || !core.Overlaps(boxes, player.Hurtboxes()) {
+		return 0
+	}
+	before := player.Combat.Health
+	switch player.TakeHit(e.AttackHit()) {
+	case core.HitMissed:
+		return 0
+	case core.HitParried:
+		e.AttackLanded = true
+		e.Stagger(ParryStagger)
+		return 0
+	}
+	e.AttackLanded = true
+	return before - player.Combat.Health
+}
+
+// AttackPlayer lands the swings of the manager's enemies on the player and
+// returns the damage dealt
+func (em *EnemyManager) AttackPlayer(player *core.PlayerRuntime) float64 {
+	dealt := 0.0
+	for i := range em.Enemies {
+		dealt += em.Enemies[i].HitPlayer(player)
+	}
+	em.TotalDamageDealt += dealt
+	return dealt
+}
+
+// AttackPlayer lands every enemy swing on the player, call it between updates
+func (em *ParallelEnemyManager) AttackPlayer(player *core.PlayerRuntime) float64 {
+	dealt := 0.0
+	for i := range em.EnemyManager {
+		dealt += em.EnemyManager[i].AttackPlayer(player)
+	}
+	return dealt
+}
+
+// ---------------- taking hits ----------------
+
+// TakePlayerHit implements core.Hurtable: it takes the damage and knockback of
+// a player attack, once per swing, and dies when out of health
+func (e *EnemyRuntime) TakePlayerHit(hit core.Hit, swing int) (float64, bool) {
+	if e.State.IsEnemyDead() || swing == e.LastSwing {
+		return 0, false
+	}
+	e.LastSwing = swing
+
+	damage := math.Min(hit.Damage, e.Health)
+	e.Health -= damage
+	if e.Health <= 0 {
+		e.die()
+		return damage, true
+	}
+	e.Statuses.Apply(hit.Status)
+
+	dir := 1.0
+	if e.Pos.X+e.Width/2 < hit.SourceX {
+		dir = -1
+	}
+	e.Physics.VelX = dir * hit.KnockbackX
+	e.Physics.VelY = -hit.KnockbackY
+	e.HurtTimer = HurtStun
+	e.State.SetEnemyState(StateHurt)
+	return damage, false
+}
+
+// die drops the enemy where it stands
+func (e *EnemyRuntime) die() {
+	e.Health = 0
+	e.Physics.VelX = 0
+	e.Statuses.Clear()
+	e.State.SetEnemyState(StateDead)
+}
+
+// Stagger leaves the enemy open for seconds, eg: after its swing was parried
+func (e *EnemyRuntime) Stagger(seconds float64) {
+	if e.State.IsEnemyDead() {
+		return
+	}
+	e.Physics.VelX = 0
+	e.HurtTimer = seconds
+	e.State.SetEnemyState(StateHurt)
+}
+
+// recoverFromHurt runs the hurt stun down and lets the enemy act again once it is over
+func (e *EnemyRuntime) recoverFromHurt(dt float64) {
+	if !e.State.IsEnemyHurt() {
+		return
+	}
+	e.HurtTimer -= dt
+	if e.HurtTimer > 0 {
+		return
+	}
+	e.HurtTimer = 0
+	if e.OnGround {
+		e.State.SetEnemyState(StateIdle)
+	} else {
+		e.State.SetEnemyState(StateFalling)
+	}
+}
+
+// RecordStrike credits a player hit on target to the manager that owns it
+func (em *ParallelEnemyManager) RecordStrike(strike core.Strike) {
+	e, ok := strike.Target.(*EnemyRuntime)
+	if !ok {
+		return
+	}
+	for i := range em.EnemyManager {
+		m := &em.EnemyManager[i]
+		for j := range m.Enemies {
+			if &m.Enemies[j] != e {
+				continue
+			}
+			m.recordDamage(strike.Damage, strike.Killed)
+			return
+		}
+	}
+}
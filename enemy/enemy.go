@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"math"
 	"player/internal/core"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type EnemyState struct {
@@ -27,19 +25,26 @@ type EnemyRuntime struct {
 	BaseStrength float64 // Original strength (before berserk modifier)
 
 	// Position and Physics
-	Pos     core.Position
-	Physics core.Physics
-	FlipX   bool    // face direction
-	Scale   float64 // sprite scale
+	core.KinematicBody
+	SpawnPos core.Position // where the level placed the enemy, it comes back here on reset
+	FlipX    bool          // face direction
+	Scale    float64       // sprite scale
 
 	// State Machine
 	CurrAnimFrame  int
-	FrameTimer     float64 // per-enemy animation timer (seconds accumulated)
+	FrameTimer     float64           // per-enemy animation timer (seconds accumulated)
+	FrameData      *core.FrameData   // frame data of the current animation
+	Frame          core.Frame        // frame data of the frame on show
+	FrameEvents    []core.FrameEvent // events of the frames the last animation update went through
 	State          EnemyState
 	PartyStatus    PartyStatus
-	Grounded       bool    // true if the enemy is touching the ground
 	PatrolDir      float64 // +1.0 = right, -1.0 = left; reverses on wall hit
 	AttackCooldown float64 // seconds remaining before next attack allowed
+	AttackLanded   bool    // the current swing already hit, it can't hit again
+	HurtTimer      float64 // seconds of hurt stun left after being hit
+	LastSwing      int     // the player swing that hit last, a swing hurts once
+	Projectile     string  // kind of core.ProjectileKinds it shoots, empty for melee enemies
+	Statuses       core.Statuses
 
 	// Berserk Mode
 	BerserkActive   bool    // true if the enemy is in berserk mode
@@ -60,6 +65,7 @@ const (
 
 	DefaultWidth          = 40
 	DefaultHeight         = 60
+	DefaultSensorDepth    = 50  // ground sensor reach below the feet
 	DefaultDetectionRange = 400 // range to detect player
 	DefaultFleeRange      = 250 // range to flee from player
 	DefaultAttackRange    = 50  // range to attack player
@@ -70,8 +76,13 @@ const (
 )
 
 func (em *EnemyManager) InitEnemy(pos core.Position) EnemyRuntime {
-	return EnemyRuntime{
-		ID:           em.generateEnemyID(),
+	return newEnemy(em.generateEnemyID(), pos)
+}
+
+// newEnemy builds an enemy with fresh stats standing at pos
+func newEnemy(id string, pos core.Position) EnemyRuntime {
+	e := EnemyRuntime{
+		ID:           id,
 		Name:         "Gideon Graves",
 		Health:       100,
 		MaxHealth:    100,
@@ -80,11 +91,8 @@ func (em *EnemyManager) InitEnemy(pos core.Position) EnemyRuntime {
 		Strength:     100,
 		BaseStrength: 100,
 		Scale:        1.0,
-		Pos: core.Position{
-			X: pos.X,
-			Y: pos.Y,
-		},
-		Physics: core.Physics{
+		SpawnPos:     pos,
+		KinematicBody: core.NewKinematicBody(pos, DefaultWidth, DefaultHeight, DefaultSensorDepth, core.Physics{
 			VelX:         0,
 			VelY:         0,
 			AccX:         EnemyAccX,
@@ -95,19 +103,20 @@ func (em *EnemyManager) InitEnemy(pos core.Position) EnemyRuntime {
 			MaxFallSpeed: EnemyMaxFallSpeed,
 			JumpForce:    EnemyJumpForce,
 			GravityScale: EnemyGravityScale,
-		},
+		}),
 		State: EnemyState{
 			Current:  StateFalling,
 			Previous: StateIdle,
 		},
 		PartyStatus:     PartySolo,
-		Grounded:        true,
 		PatrolDir:       1.0,
 		AttackCooldown:  0,
 		BerserkActive:   false,
 		BerserkDuration: 0,
 		BeserkCoolDown:  0,
 	}
+	e.OnGround = true
+	return e
 }
 
 func (em *EnemyManager) generateEnemyID() string {
@@ -118,27 +127,6 @@ func (em *EnemyManager) generateEnemyID() string {
 	return fmt.Sprintf("E-%d", em.nextID)
 }
 
-// GetBounds implements core.Collider so enemies can be tracked in the quadtree.
-func (e *EnemyRuntime) GetBounds() core.AABB {
-	return core.AABB{
-		X:      e.Pos.X,
-		Y:      e.Pos.Y,
-		Width:  DefaultWidth,
-		Height: DefaultHeight,
-	}
-}
-
-// getGroundSensor returns a thin AABB just below the enemy's feet
-// used to detect whether the enemy is near ground before physics resolves.
-func (e *EnemyRuntime) getGroundSensor() core.AABB {
-	return core.AABB{
-		X:      e.Pos.X,
-		Y:      e.Pos.Y + DefaultHeight,
-		Width:  DefaultWidth,
-		Height: 50,
-	}
-}
-
 // decideAction runs the 3-tier AI and returns a horizontal input direction
 // (-1 left, 0 stop, +1 right) and whether the enemy wants to attack this frame.
 // This replaces the role of InputState in UpdatePlayer.
@@ -146,6 +134,11 @@ func (e *EnemyRuntime) decideAction(player *core.PlayerRuntime) (inputX float64,
 	if e.State.IsEnemyDead() {
 		return 0, false
 	}
+	// a swing or a shot plays out, the animation puts the enemy back to idle
+	// when it ends, and a hurt or stunned enemy waits it out
+	if e.State.IsEnemyAttacking() || e.State.IsEnemyShooting() || e.State.IsEnemyHurt() || e.Statuses.Stunned() {
+		return 0, false
+	}
 
 	dx := player.Pos.X - e.Pos.X
 	dist := math.Abs(dx)
@@ -159,8 +152,12 @@ func (e *EnemyRuntime) decideAction(player *core.PlayerRuntime) (inputX float64,
 		return 1, false
 	}
 
-	// Tier 2: Hunt / Attack — player is within detection range
-	if dist < DefaultDetectionRange {
+	// Tier 2: Hunt / Attack — player is within detection range, a dazed
+	// enemy (low IQ) only notices the player up close
+	if dist < DefaultDetectionRange*e.IQ/100 {
+		if e.canShoot(player, dist) {
+			return 0, true // stop and shoot
+		}
 		if dist < DefaultAttackRange && e.AttackCooldown <= 0 && e.State.CanEnemyAttack() {
 			return 0, true // stop and attack
 		}
@@ -176,33 +173,34 @@ func (e *EnemyRuntime) decideAction(player *core.PlayerRuntime) (inputX float64,
 	return e.PatrolDir, false
 }
 
-// Update advances the enemy simulation by one frame: AI decisions, physics
-// integration, platform collision resolution, and state machine transitions.
+// Update advances the enemy simulation by one step of dt seconds: AI decisions,
+// physics integration, platform collision resolution, and state machine transitions.
 // It mirrors the structure of core.UpdatePlayer but replaces InputState with
-// the 3-tier AI from decideAction.
-func (e *EnemyRuntime) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtree) {
+// the 3-tier AI from decideAction. It returns the damage status effects and
+// hazards did this step and whether they killed the enemy, for the manager's stats.
+func (e *EnemyRuntime) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtree, dt float64) (float64, bool) {
 	e.State.Previous = e.State.Current
+	e.PrevPos = e.Pos
 	// 1. Guard: dead enemies don't simulate
 	if e.State.IsEnemyDead() {
-		return
+		return 0, false
 	}
 
 	// 2. Time management
-	tps := float64(ebiten.TPS())
-	if tps <= 0 {
-		tps = 60
-	}
-
-	dt := 1.0 / tps
-	dtUnits := 100.0 / tps
+	dtUnits := 100.0 * dt
 
-	// 3. Tick attack cooldown
+	// 3. Tick attack cooldown, hurt stun and status effects
 	if e.AttackCooldown > 0 {
 		e.AttackCooldown -= dt
 		if e.AttackCooldown < 0 {
 			e.AttackCooldown = 0
 		}
 	}
+	e.recoverFromHurt(dt)
+	hurt, killed := e.updateStatuses(dt)
+	if killed {
+		return hurt, true
+	}
 
 	// 4. AI decision (replaces InputState)
 	inputX, wantsAttack := e.decideAction(player)
@@ -218,26 +216,18 @@ func (e *EnemyRuntime) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtr
 		targetVX = inputX * e.Physics.MaxRunSpeed
 	}
 
-	// 7. X physics (acceleration / friction)
-	accX := e.Physics.AccX * dtUnits
-	decX := e.Physics.DecX * dtUnits
+	// 7. X physics (acceleration / friction), scaled by the ground material and status effects
+	mat := e.GroundMaterial()
+	targetVX *= mat.SpeedScale * e.Statuses.SpeedScale()
+	accX := e.Physics.AccX * dtUnits * mat.Accel
+	decX := e.Physics.DecX * dtUnits * mat.Friction
 
 	step := accX
-	if inputX == 0 && e.Grounded {
+	if inputX == 0 && e.OnGround {
 		step = decX // friction when standing still on ground
 	}
 
-	if math.Abs(e.Physics.VelX) > e.Physics.MaxSpeed && !e.BerserkActive {
-		// Decelerate from berserk/run speed back to normal max
-		step = decX
-		if e.Physics.VelX > 0 {
-			e.Physics.VelX = core.ReduceRight(e.Physics.VelX, e.Physics.MaxSpeed, step)
-		} else if e.Physics.VelX < 0 {
-			e.Physics.VelX = core.ReduceLeft(e.Physics.VelX, -e.Physics.MaxSpeed, step)
-		}
-	} else {
-		e.Physics.VelX = core.Approach(e.Physics.VelX, targetVX, step)
-	}
+	e.SteerX(targetVX, step, decX, e.BerserkActive)
 
 	// 8. FlipX
 	if inputX < 0 {
@@ -247,93 +237,44 @@ func (e *EnemyRuntime) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtr
 	}
 
 	// 9. Y physics (gravity)
-	e.Physics.VelY += e.Physics.GravityScale * dtUnits
-
-	// 10. Apply X, resolve X collisions
-	e.Pos.X += e.Physics.VelX * dt
-	if qt != nil {
-		objs := qt.Retrieve(e.GetBounds())
-		for _, obj := range objs {
-			if _, ok := obj.(*core.Platform); ok {
-				bounds := obj.GetBounds()
-				if e.GetBounds().Intersects(bounds) {
-					if e.Physics.VelX > 0 { // moving right
-						e.Pos.X = bounds.X - DefaultWidth
-					} else if e.Physics.VelX < 0 { // moving left
-						e.Pos.X = bounds.X + bounds.Width
-					}
-					e.Physics.VelX = 0
-					// Patrol: reverse direction on wall hit
-					if e.State.IsEnemyPatrolling() {
-						e.PatrolDir = -e.PatrolDir
-					}
-				}
-			}
-		}
-	}
+	e.ApplyGravity(dt)
 
-	// 11. Apply Y (capped at MaxFallSpeed), resolve Y collisions
-	if e.Physics.VelY < e.Physics.MaxFallSpeed {
-		e.Pos.Y += e.Physics.VelY * dt
-	} else {
-		e.Physics.VelY = e.Physics.MaxFallSpeed
-		e.Pos.Y += e.Physics.VelY * dt
-	}
-
-	onGround := false
-	detectGround := false
-
-	if qt != nil {
-		objs := qt.Retrieve(e.GetBounds())
-		sensor := e.getGroundSensor()
-		for _, obj := range objs {
-			if _, ok := obj.(*core.Platform); ok {
-				bounds := obj.GetBounds()
-
-				// Ground detection (sensor only)
-				if sensor.Intersects(bounds) {
-					detectGround = true
-				}
-
-				// Physics collision (body only)
-				if e.GetBounds().Intersects(bounds) {
-					if e.Physics.VelY > 0 { // landing
-						e.Pos.Y = bounds.Y - DefaultHeight
-						onGround = true
-						e.Physics.VelY = 0
-					} else if e.Physics.VelY < 0 { // head bonk
-						e.Pos.Y = bounds.Y + bounds.Height
-						e.Physics.VelY = 0
-					}
-				}
-			}
+	// 10. Move, sweeping against walls, floors and ceilings
+	if _, hitWall := e.Move(qt, dt); hitWall {
+		// Patrol: reverse direction on wall hit
+		if e.State.IsEnemyPatrolling() {
+			e.PatrolDir = -e.PatrolDir
 		}
 	}
-
-	// 12. Update grounded state
-	e.Grounded = onGround
-
-	// 13. Attack
-	if wantsAttack {
+	onGround := e.OnGround
+	detectGround := e.NearGround
+
+	// 11. Attack, or shoot for ranged enemies
+	if wantsAttack && e.Projectile != "" {
+		e.State.SetEnemyState(StateShooting)
+		e.AttackCooldown = ShootCooldown
+		e.FlipX = player.Pos.X < e.Pos.X // aim at the player
+	} else if wantsAttack {
 		e.State.SetEnemyState(StateAttacking)
 		e.AttackCooldown = 1.0
+		e.AttackLanded = false
 	}
 
-	// 14. Physics-driven state transitions
+	// 12. Physics-driven state transitions
 	if detectGround {
 		if onGround && e.State.IsEnemyFalling() {
 			e.State.SetEnemyState(StateIdle)
 		}
 	} else {
 		// Airborne: transition to falling if not already jumping/falling
-		if e.Physics.VelY > 0 && !e.State.IsEnemyJumping() && !e.State.IsEnemyFalling() {
+		if e.Physics.VelY > 0 && !e.State.IsEnemyJumping() && !e.State.IsEnemyFalling() && !e.State.IsEnemyHurt() {
 			e.State.SetEnemyState(StateFalling)
 		}
 	}
 
-	// 15. Update spatial index (auto-inserts on first call)
+	// 13. Update spatial index (auto-inserts on first call)
 	if qt != nil {
 		qt.Update(e)
 	}
-
+	return hurt, false
 }
@@ -3,8 +3,6 @@ package enemy
 import (
 	"image"
 	"player/internal/core"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // image dimensions
@@ -21,11 +19,8 @@ const (
 	// frameHeight_large   = 160 // this frame is used
 	// frameHeight_maximum = 200 // this frame is currently not used
 
-	enemySpriteSheetPath = "../assets/GideonGraves.png"
 )
 
-var enemySpriteSheet *ebiten.Image
-
 // ---------------- animation ----------------
 type Animation struct {
 	CurrentState         int // Use the enum instead of embedded PlayerState
@@ -34,12 +29,38 @@ type Animation struct {
 	AnimStartFrame       int
 	FrameWidth           int
 	FrameHeight          int
-	FrameTimer           float64 // time in seconds that the current frame has been displayed for ie: if FrameTimer is x, then the current frame is displayed for x/AnimationSpeed seconds
-	AnimationSpeed       float64 // no of frames to display per second in seconds
-	Looping              bool    // true if the animation should loop
+	FrameTimer           float64         // time in seconds that the current frame has been displayed for ie: if FrameTimer is x, then the current frame is displayed for x/AnimationSpeed seconds
+	AnimationSpeed       float64         // no of frames to display per second in seconds
+	Looping              bool            // true if the animation should loop
+	Frames               *core.FrameData // hitboxes, hurtboxes and events per frame, from assets/framedata.json
 }
 
-func InitEnemyAnimations() map[int]Animation {
+// SpriteRect is where frame of the animation sits on the sprite sheet
+func (a Animation) SpriteRect(frame int) image.Rectangle {
+	x := frame * a.FrameWidth
+	y := a.SpriteSheetYPosition * frameHeight_minimum
+	return image.Rect(x, y, x+a.FrameWidth, y+a.FrameHeight)
+}
+
+// EnemyFrameData is the enemies' section of the frame data file
+const EnemyFrameData = "enemy"
+
+// EnemyAnimationNames are the names the frame data file uses for the enemy animations
+var EnemyAnimationNames = map[string]int{
+	"idle":       StateIdle,
+	"patrolling": StatePatrolling,
+	"hunting":    StateHunting,
+	"jumping":    StateJumping,
+	"falling":    StateFalling,
+	"landing":    StateLanding,
+	"attacking":  StateAttacking,
+	"defending":  StateDefending,
+	"dead":       StateDead,
+	"shooting":   StateShooting,
+}
+
+// InitEnemyAnimations builds the enemy animations with their frame data
+func InitEnemyAnimations() (map[int]Animation, error) {
 	animations := make(map[int]Animation)
 	animations[int(StateIdle)] = Animation{
 		CurrentState:         StateIdle,
@@ -146,6 +167,19 @@ func InitEnemyAnimations() map[int]Animation {
 		Looping:              false,
 	}
 
+	// animation yet to make, borrows the attack swing
+	animations[int(StateShooting)] = Animation{
+		CurrentState:         StateShooting,
+		SpriteSheetYPosition: 19,
+		TotalFrames:          8,
+		AnimStartFrame:       0,
+		FrameWidth:           frameWidth_large,
+		FrameHeight:          frameHeight_small,
+		FrameTimer:           0,
+		AnimationSpeed:       10,
+		Looping:              false,
+	}
+
 	// animation yet to make
 	animations[int(StateDead)] = Animation{
 		CurrentState:         StateDead,
@@ -158,30 +192,46 @@ func InitEnemyAnimations() map[int]Animation {
 		Looping:              false,
 	}
 
-	return animations
+	frameData, err := core.DefaultFrameData()
+	if err != nil {
+		return nil, err
+	}
+	err = core.AttachFrameData(EnemyFrameData, frameData[EnemyFrameData], EnemyAnimationNames,
+		func(key int) (int, bool) {
+			anim, ok := animations[key]
+			return anim.TotalFrames, ok
+		},
+		func(key int, data *core.FrameData) {
+			anim := animations[key]
+			anim.Frames = data
+			animations[key] = anim
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return animations, nil
 }
 
 // UpdateEnemyAnimation advances the animation frame for this enemy based on
 // time accumulation. Uses per-enemy FrameTimer (not the shared Animation.FrameTimer)
 // because the animation map is shared across all enemies.
-// Mirrors PlayerRuntime.UpdateAnimation (internal/core/animation.go:287-328).
-func (e *EnemyRuntime) UpdateEnemyAnimation(animations *map[int]Animation) {
-	tps := float64(ebiten.TPS())
-	if tps <= 0 {
-		tps = 60
-	}
-	dt := 1.0 / tps
+// Mirrors PlayerRuntime.UpdateAnimation (internal/core/animation.go).
+func (e *EnemyRuntime) UpdateEnemyAnimation(animations *map[int]Animation, dt float64) {
+	defer e.showFrame(animations)
 
 	currState := e.State.Current
 	anim, ok := (*animations)[currState]
 	if !ok {
 		anim = (*animations)[StateIdle]
 	}
+	e.FrameEvents = e.FrameEvents[:0]
 
 	// On state change: reset to animation's start frame
 	if e.State.Previous != e.State.Current {
 		e.CurrAnimFrame = anim.AnimStartFrame
 		e.FrameTimer = 0
+		e.FrameEvents = append(e.FrameEvents, anim.Frames.At(0).Events...)
 	}
 
 	timePerFrame := 1.0 / anim.AnimationSpeed
@@ -189,6 +239,7 @@ func (e *EnemyRuntime) UpdateEnemyAnimation(animations *map[int]Animation) {
 
 	for e.FrameTimer >= timePerFrame {
 		e.FrameTimer -= timePerFrame
+		prevFrame := e.CurrAnimFrame
 		e.CurrAnimFrame++
 
 		if e.CurrAnimFrame >= anim.AnimStartFrame+anim.TotalFrames {
@@ -198,6 +249,7 @@ func (e *EnemyRuntime) UpdateEnemyAnimation(animations *map[int]Animation) {
 				// Non-looping end behavior by state
 				if e.State.IsEnemyState(StateLanding) ||
 					e.State.IsEnemyAttacking() ||
+					e.State.IsEnemyShooting() ||
 					e.State.IsEnemyDefending() {
 					e.State.SetEnemyState(StateIdle)
 					e.CurrAnimFrame = 0
@@ -213,48 +265,24 @@ func (e *EnemyRuntime) UpdateEnemyAnimation(animations *map[int]Animation) {
 				e.CurrAnimFrame = anim.AnimStartFrame + anim.TotalFrames - 1
 			}
 		}
+
+		if e.CurrAnimFrame != prevFrame {
+			e.FrameEvents = append(e.FrameEvents, anim.Frames.At(e.CurrAnimFrame-anim.AnimStartFrame).Events...)
+		}
 	}
 }
 
-func (e *EnemyRuntime) DrawEnemyAnimation(screen *ebiten.Image, img *ebiten.Image, animations *map[int]Animation, camera core.Camera) {
-	bounds := e.GetBounds()
-
-	// Look up animation for current state, fallback to idle
-	currState := e.State.Current
-	anim, ok := (*animations)[currState]
+// showFrame looks up the frame data of the frame the enemy shows
+func (e *EnemyRuntime) showFrame(animations *map[int]Animation) {
+	anim, ok := (*animations)[e.State.Current]
 	if !ok {
 		anim = (*animations)[StateIdle]
 	}
+	e.FrameData = anim.Frames
+	e.Frame = anim.Frames.At(e.CurrAnimFrame - anim.AnimStartFrame)
+}
 
-	width := anim.FrameWidth
-	height := anim.FrameHeight
-	frameX := e.CurrAnimFrame * width
-	frameY := anim.SpriteSheetYPosition * frameHeight_minimum
-
-	rect := image.Rect(frameX, frameY, frameX+width, frameY+height)
-	subImage := img.SubImage(rect).(*ebiten.Image)
-
-	op := &ebiten.DrawImageOptions{}
-
-	if e.FlipX {
-		// Flip horizontally
-		op.GeoM.Scale(-e.Scale, e.Scale)
-		// Translate back because flipping moves the image to the left of the axis
-		op.GeoM.Translate(float64(width)*e.Scale, 0)
-	} else {
-		op.GeoM.Scale(e.Scale, e.Scale)
-	}
-
-	// Center horizontally on collision box
-	drawX := bounds.X + (bounds.Width-float64(width)*e.Scale)/2
-	// Align bottom to collision box bottom
-	drawY := bounds.Y + (bounds.Height - float64(height)*e.Scale)
-
-	// Apply camera offset
-	drawX -= camera.Pos.X
-	drawY -= camera.Pos.Y
-
-	op.GeoM.Translate(drawX, drawY)
-
-	screen.DrawImage(subImage, op)
+// Hurtboxes implements core.Hurtable: where the enemy can be hurt on the frame it shows
+func (e *EnemyRuntime) Hurtboxes() []core.AABB {
+	return e.Frame.HurtboxesOn(e.GetBounds(), e.FlipX)
 }
@@ -37,14 +37,43 @@ func (em *EnemyManager) InitEnemyManager(id string) EnemyManager {
 	}
 }
 
-func (em *EnemyManager) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtree) {
+func (em *EnemyManager) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtree, dt float64) {
 	for i := range em.Enemies {
-		em.Enemies[i].Update(player, qt)
+		// burns, poison and hazard tiles hurt without a strike, count them here
+		if hurt, killed := em.Enemies[i].Update(player, qt, dt); hurt > 0 {
+			em.recordDamage(hurt, killed)
+		}
 	}
 }
 
-func (em *EnemyManager) UpdateAnimations(animations map[int]Animation) {
+// recordDamage adds damage one of the manager's enemies took to the stats,
+// and its death if the damage killed it
+func (em *EnemyManager) recordDamage(damage float64, killed bool) {
+	em.TotalDamageTaken += damage
+	if killed {
+		em.TotalDeaths++
+	}
+}
+
+// ResetRegion puts every enemy that spawned inside region back at its spawn
+// point with full stats, dead ones included. It returns how many were reset.
+func (em *EnemyManager) ResetRegion(region core.AABB) int {
+	n := 0
+	for i := range em.Enemies {
+		e := &em.Enemies[i]
+		if !region.Contains(e.SpawnPos.X, e.SpawnPos.Y) {
+			continue
+		}
+		projectile := e.Projectile
+		*e = newEnemy(e.ID, e.SpawnPos) // same enemy, keeps its ID and its weapon
+		e.Projectile = projectile
+		n++
+	}
+	return n
+}
+
+func (em *EnemyManager) UpdateAnimations(animations map[int]Animation, dt float64) {
 	for i := range em.Enemies {
-		em.Enemies[i].UpdateEnemyAnimation(&animations)
+		em.Enemies[i].UpdateEnemyAnimation(&animations, dt)
 	}
 }
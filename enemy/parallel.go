@@ -6,17 +6,14 @@ import (
 	"player/internal/core"
 	"runtime"
 	"sync"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const mxEnInManager = 10
 
 // ParallelEnemyManager extends EnemyManager with parallel processing
 type ParallelEnemyManager struct {
-	EnemyManager  []EnemyManager
-	enemyBasicImg *ebiten.Image
-	Animations    map[int]Animation
+	EnemyManager []EnemyManager
+	Animations   map[int]Animation
 
 	PartyManager PartyManager
 
@@ -24,6 +21,7 @@ type ParallelEnemyManager struct {
 	wg          sync.WaitGroup
 	mutex       sync.Mutex
 	WorkerCount int
+	Sequential  bool // update managers in order on the calling goroutine (headless / deterministic runs)
 
 	// worker pool channels
 	workSignal []chan struct{} // per-worker channel to signal "start updating"
@@ -33,46 +31,79 @@ type ParallelEnemyManager struct {
 	// per-frame parameters set by main goroutine before signalling workers
 	framePlayer *core.PlayerRuntime
 	frameQt     *core.DynamicQuadtree
+	frameDt     float64
 }
 
 // DefaultParallelConfig returns sensible defaults
-func DefaultParallelConfig(player *core.PlayerRuntime, qt *core.DynamicQuadtree) ParallelEnemyManager {
+func DefaultParallelConfig(player *core.PlayerRuntime, qt *core.DynamicQuadtree) (*ParallelEnemyManager, error) {
 	workerCount := runtime.NumCPU() - 1
 	if _, err := os.Stat("/.dockerenv"); err == nil {
-		fmt.Println("Running in Docker")
 		workerCount = 1
 	}
 
+	return newParallelEnemyManager(player, qt, workerCount)
+}
+
+// HeadlessParallelConfig returns a manager that updates its enemies
+// sequentially, so it steps the same way every run
+func HeadlessParallelConfig(player *core.PlayerRuntime, qt *core.DynamicQuadtree) (*ParallelEnemyManager, error) {
+	em, err := newParallelEnemyManager(player, qt, 1)
+	if err != nil {
+		return nil, err
+	}
+	em.Sequential = true
+	return em, nil
+}
+
+func newParallelEnemyManager(player *core.PlayerRuntime, qt *core.DynamicQuadtree, workerCount int) (*ParallelEnemyManager, error) {
 	managers := make([]EnemyManager, workerCount)
 	var base EnemyManager
 	for i := 0; i < workerCount; i++ {
 		managers[i] = base.InitEnemyManager(fmt.Sprintf("EM-%d", i))
 	}
 
-	return ParallelEnemyManager{
-		EnemyManager:  managers,
-		enemyBasicImg: core.LoadImage(enemySpriteSheetPath),
-		Animations:    InitEnemyAnimations(),
-		PartyManager:  InitPartyManager(),
-		WorkerCount:   workerCount,
-		framePlayer:   player,
-		frameQt:       qt,
+	animations, err := InitEnemyAnimations()
+	if err != nil {
+		return nil, err
 	}
+	return &ParallelEnemyManager{
+		EnemyManager: managers,
+		Animations:   animations,
+		PartyManager: InitPartyManager(),
+		WorkerCount:  workerCount,
+		framePlayer:  player,
+		frameQt:      qt,
+	}, nil
 }
 
 // Shutdown signals all persistent workers to exit and waits for them to finish.
+// It is safe to call more than once.
 func (em *ParallelEnemyManager) Shutdown() {
 	if em.quit != nil {
 		close(em.quit)
 		em.wg.Wait()
+		em.quit = nil
 	}
 	em.EnemyManager = nil
 }
 
-func (em *ParallelEnemyManager) AddEnemyToLevel(level []core.Platform) {
-	fmt.Println("Adding enemies to level")
+// Reset stops the workers and drops every enemy and party, ready for the next level
+func (em *ParallelEnemyManager) Reset() {
+	em.Shutdown()
+	em.workSignal = nil
+	em.done = nil
+
+	em.EnemyManager = make([]EnemyManager, em.WorkerCount)
+	var base EnemyManager
+	for i := range em.EnemyManager {
+		em.EnemyManager[i] = base.InitEnemyManager(fmt.Sprintf("EM-%d", i))
+	}
+	em.PartyManager = InitPartyManager()
+}
+
+// AddEnemyToLevel spawns an enemy for every enemy entity a level loader found
+func (em *ParallelEnemyManager) AddEnemyToLevel(spawns []core.EntitySpawn) {
 	if em == nil {
-		fmt.Println("Enemy Manager is nil")
 		return
 	}
 	if em.EnemyManager == nil {
@@ -80,15 +111,17 @@ func (em *ParallelEnemyManager) AddEnemyToLevel(level []core.Platform) {
 	}
 
 	// 1. check level for enemy
-	for _, platform := range level {
-		if isEnemy(platform.TileInfo.TileType) {
+	for _, spawn := range spawns {
+		if isEnemy(spawn.Kind) {
 			// 2. if found the enemy put it into the enemy manager accordingly.
-			em.spawnEnemy(platform.X, platform.Y)
+			em.spawnEnemy(spawn)
 		}
 	}
 
 	// 3. Start persistent worker goroutines (once, at level load)
-	em.startWorkers()
+	if !em.Sequential {
+		em.startWorkers()
+	}
 }
 
 // startWorkers spawns one long-lived goroutine per EnemyManager.
@@ -104,7 +137,6 @@ func (em *ParallelEnemyManager) startWorkers() {
 		em.wg.Add(1)
 		go em.worker(i)
 	}
-	fmt.Printf("Started %d persistent enemy workers\n", workerCount)
 }
 
 // worker is a long-lived goroutine that waits for a signal each frame.
@@ -118,8 +150,8 @@ func (em *ParallelEnemyManager) worker(id int) {
 		case <-em.workSignal[id]:
 			em.mutex.Lock()
 			if id < len(em.EnemyManager) {
-				em.EnemyManager[id].Update(em.framePlayer, em.frameQt)
-				em.EnemyManager[id].UpdateAnimations(em.Animations)
+				em.EnemyManager[id].Update(em.framePlayer, em.frameQt, em.frameDt)
+				em.EnemyManager[id].UpdateAnimations(em.Animations, em.frameDt)
 			}
 			em.mutex.Unlock()
 			em.done <- struct{}{}
@@ -127,9 +159,17 @@ func (em *ParallelEnemyManager) worker(id int) {
 	}
 }
 
-// Update is called every frame. It signals all workers to process their
-// enemies, then waits for all of them to finish before returning.
-func (em *ParallelEnemyManager) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtree) {
+// Update is called every simulation step. It signals all workers to process
+// their enemies, then waits for all of them to finish before returning.
+func (em *ParallelEnemyManager) Update(player *core.PlayerRuntime, qt *core.DynamicQuadtree, dt float64) {
+	if em.Sequential {
+		for i := range em.EnemyManager {
+			em.EnemyManager[i].Update(player, qt, dt)
+			em.EnemyManager[i].UpdateAnimations(em.Animations, dt)
+		}
+		return
+	}
+
 	if em.workSignal == nil {
 		return
 	}
@@ -137,6 +177,7 @@ func (em *ParallelEnemyManager) Update(player *core.PlayerRuntime, qt *core.Dyna
 	// Store frame params before workers read them (main goroutine owns these writes)
 	em.framePlayer = player
 	em.frameQt = qt
+	em.frameDt = dt
 
 	// Signal all workers to start
 	for i := range em.workSignal {
@@ -149,19 +190,32 @@ func (em *ParallelEnemyManager) Update(player *core.PlayerRuntime, qt *core.Dyna
 	}
 }
 
-func isEnemy(t core.TileType) bool {
-	switch t {
-	case core.EnemyBasic:
+// ResetRegion resets the enemies that spawned inside region, eg: when the
+// player respawns at a checkpoint. Call it between updates, never during one.
+func (em *ParallelEnemyManager) ResetRegion(region core.AABB) int {
+	n := 0
+	for i := range em.EnemyManager {
+		n += em.EnemyManager[i].ResetRegion(region)
+	}
+	return n
+}
+
+func isEnemy(k core.EntityKind) bool {
+	switch k {
+	case core.EntityEnemyBasic, core.EntityEnemyRanged:
 		return true
 	default:
 		return false
 	}
 }
 
-func (em *ParallelEnemyManager) spawnEnemy(x, y float64) {
+func (em *ParallelEnemyManager) spawnEnemy(spawn core.EntitySpawn) {
+	pos := core.Position{X: spawn.X, Y: spawn.Y}
 	for i := range em.EnemyManager {
 		if len(em.EnemyManager[i].Enemies) < mxEnInManager {
-			em.EnemyManager[i].Enemies = append(em.EnemyManager[i].Enemies, em.EnemyManager[i].InitEnemy(core.Position{X: x, Y: y}))
+			e := em.EnemyManager[i].InitEnemy(pos)
+			e.arm(spawn)
+			em.EnemyManager[i].Enemies = append(em.EnemyManager[i].Enemies, e)
 			return
 		}
 	}
@@ -169,14 +223,8 @@ func (em *ParallelEnemyManager) spawnEnemy(x, y float64) {
 	var base EnemyManager
 	newMgrID := fmt.Sprintf("EM-%d", len(em.EnemyManager))
 	newMgr := base.InitEnemyManager(newMgrID)
-	newMgr.Enemies = append(newMgr.Enemies, newMgr.InitEnemy(core.Position{X: x, Y: y}))
+	e := newMgr.InitEnemy(pos)
+	e.arm(spawn)
+	newMgr.Enemies = append(newMgr.Enemies, e)
 	em.EnemyManager = append(em.EnemyManager, newMgr)
 }
-
-func (em *ParallelEnemyManager) DrawEnemies(screen *ebiten.Image, camera core.Camera) {
-	for _, enemyManager := range em.EnemyManager {
-		for i := range enemyManager.Enemies {
-			enemyManager.Enemies[i].DrawEnemyAnimation(screen, em.enemyBasicImg, &em.Animations, camera)
-		}
-	}
-}
@@ -0,0 +1,28 @@
+package enemy
+
+import (
+	"testing"
+
+	"player/internal/core"
+)
+
+func TestShutdownTwice(t *testing.T) {
+	player, err := core.InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	em, err := newParallelEnemyManager(&player, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	em.AddEnemyToLevel(nil) // starts the workers
+
+	em.Shutdown()
+	em.Shutdown()
+
+	// a reset after a shutdown, and a shutdown after that, are fine too
+	em.Reset()
+	em.AddEnemyToLevel(nil)
+	em.Reset()
+	em.Shutdown()
+}
@@ -0,0 +1,40 @@
+package enemy
+
+import (
+	"math"
+
+	"player/internal/core"
+)
+
+// ------------------------ ranged constants ------------------------
+// Ranged enemies are placed as enemyRanged objects, the core.ProjectileProperty
+// custom property picks what they shoot from core.ProjectileKinds, default arrow.
+// The shot leaves on the spawnProjectile event of their shooting animation.
+const (
+	DefaultEnemyProjectile = "arrow"
+
+	DefaultShootRange  = 350 // range to shoot at the player from
+	DefaultShootHeight = 80  // how far above or below the player may be and still be shot at
+	ShootCooldown      = 2.0 // seconds between shots
+)
+
+// arm gives an enemy the weapon its level object asks for
+func (e *EnemyRuntime) arm(spawn core.EntitySpawn) {
+	if spawn.Kind != core.EntityEnemyRanged {
+		return
+	}
+	e.Projectile = DefaultEnemyProjectile
+	// map files with unknown kinds are turned down by core.LoadLevelFile
+	if kind := spawn.Properties[core.ProjectileProperty]; kind != "" {
+		if _, known := core.ProjectileKinds[kind]; known {
+			e.Projectile = kind
+		}
+	}
+}
+
+// canShoot reports whether a ranged enemy has the player in its sights
+func (e *EnemyRuntime) canShoot(player *core.PlayerRuntime, dist float64) bool {
+	return e.Projectile != "" && dist < DefaultShootRange &&
+		math.Abs(player.Pos.Y+player.Height-(e.Pos.Y+e.Height)) < DefaultShootHeight &&
+		e.AttackCooldown <= 0 && e.State.CanEnemyAttack()
+}
@@ -16,6 +16,8 @@ const (
 	StateAttacking
 	StateDefending
 	StateDead
+	StateHurt     // knocked back by a hit, no animation of its own yet
+	StateShooting // ranged enemies firing their projectile
 )
 
 // Checking Enemy State
@@ -75,6 +77,14 @@ func (s *EnemyState) IsEnemyDead() bool {
 	return s.Current == StateDead
 }
 
+func (s *EnemyState) IsEnemyHurt() bool {
+	return s.Current == StateHurt
+}
+
+func (s *EnemyState) IsEnemyShooting() bool {
+	return s.Current == StateShooting
+}
+
 func (s *EnemyState) IsEnemyGrounded() bool {
 	return s.Current != StateJumping && s.Current != StateFalling
 }
@@ -121,7 +131,7 @@ const (
 
 // check if AI can take an action
 func (s *EnemyState) CanEnemyMove() bool {
-	return s.Current != StateDead && s.Current != StateResting && s.Current != StateAttacking
+	return s.Current != StateDead && s.Current != StateResting && s.Current != StateAttacking && s.Current != StateHurt && s.Current != StateShooting
 }
 
 func (s *EnemyState) CanEnemyJump() bool {
@@ -0,0 +1,34 @@
+package enemy
+
+import (
+	"math"
+
+	"player/internal/core"
+)
+
+// updateStatuses ticks the enemy's status effects, picks up the one of the
+// ground it stands on, burns it on hazard tiles like the player and scales its
+// stats by what is on. It returns the damage the ticks and the hazard did and
+// whether that killed it.
+func (e *EnemyRuntime) updateStatuses(dt float64) (float64, bool) {
+	damage := 0.0
+	if e.OnGround {
+		m := e.GroundMaterial()
+		e.Statuses.Apply(m.Status)
+		if m.Hazard {
+			damage += m.DamagePerSecond * dt
+		}
+	} else if m := core.MaterialOf(e.Medium); m.Hazard {
+		e.Statuses.Apply(m.Status)
+		damage += m.DamagePerSecond * dt
+	}
+	damage = math.Min(damage+e.Statuses.Update(dt), e.Health)
+	e.Health -= damage
+	e.Strength = e.BaseStrength * e.Statuses.StrengthScale()
+	e.IQ = e.BaseIQ * e.Statuses.IQScale()
+	if damage > 0 && e.Health <= 0 {
+		e.die()
+		return damage, true
+	}
+	return damage, false
+}
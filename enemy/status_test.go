@@ -0,0 +1,73 @@
+package enemy
+
+import (
+	"testing"
+
+	"player/internal/core"
+)
+
+func TestEnemyBurnsOnLava(t *testing.T) {
+	e := newEnemy("e", core.Position{})
+	e.OnGround, e.NearGround = true, true
+	e.Ground = core.Larva
+
+	const dt = 0.25 // shorter than a burn tick, only the contact damage lands
+	if _, killed := e.updateStatuses(dt); killed {
+		t.Fatal("enemy died from a quarter second on lava")
+	}
+	want := e.MaxHealth - core.MaterialOf(core.Larva).DamagePerSecond*dt
+	if e.Health != want {
+		t.Fatalf("health = %v, want %v", e.Health, want)
+	}
+	if !e.Statuses.Has(core.StatusBurn) {
+		t.Fatal("enemy on lava is not burning")
+	}
+}
+
+func TestEnemyDiesOnLava(t *testing.T) {
+	e := newEnemy("e", core.Position{})
+	e.OnGround, e.NearGround = true, true
+	e.Ground = core.Larva
+	e.Health = 1
+
+	if _, killed := e.updateStatuses(0.1); !killed {
+		t.Fatal("lava did not kill an enemy on its last health")
+	}
+	if !e.State.IsEnemyDead() {
+		t.Fatal("enemy killed by lava is not dead")
+	}
+}
+
+// TestHazardDeathCounts checks deaths from statuses and hazards reach the
+// manager's stats like deaths from the player's strikes
+func TestHazardDeathCounts(t *testing.T) {
+	var base EnemyManager
+	m := base.InitEnemyManager("EM-0")
+	e := newEnemy("e", core.Position{})
+	e.OnGround, e.NearGround = true, true
+	e.Ground = core.Larva
+	e.Health = 1
+	m.Enemies = append(m.Enemies, e)
+
+	player, err := core.InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Update(&player, nil, 0.1)
+
+	if !m.Enemies[0].State.IsEnemyDead() {
+		t.Fatal("lava did not kill an enemy on its last health")
+	}
+	if m.TotalDeaths != 1 {
+		t.Fatalf("TotalDeaths = %d, want 1", m.TotalDeaths)
+	}
+	if m.TotalDamageTaken != 1 {
+		t.Fatalf("TotalDamageTaken = %v, want the 1 health it had", m.TotalDamageTaken)
+	}
+
+	// a dead enemy takes no more damage and doesn't die twice
+	m.Update(&player, nil, 0.1)
+	if m.TotalDeaths != 1 || m.TotalDamageTaken != 1 {
+		t.Fatalf("stats moved after death: deaths %d, damage %v", m.TotalDeaths, m.TotalDamageTaken)
+	}
+}
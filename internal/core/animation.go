@@ -1,10 +1,6 @@
 package core
 
-import (
-	"image"
-
-	"github.com/hajimehoshi/ebiten/v2"
-)
+import "image"
 
 // ---------------- states ----------------
 const (
@@ -23,12 +19,15 @@ const (
 	SpecialAttack4
 	WeakAttackInAir
 	StrongAttackInAir
-	// not used yet
 	Damaged
 	Dead
 	MenuOpen
 	Defense
 	UsePotion
+	Dashing
+	WallSliding
+	WallJumping
+	Swimming
 )
 
 // ---------------- animation ----------------
@@ -39,9 +38,47 @@ type Animation struct {
 	AnimStartFrame       int
 	FrameWidth           int
 	FrameHeight          int
-	FrameTimer           float64 // time in seconds that the current frame has been displayed for ie: if FrameTimer is x, then the current frame is displayed for x/AnimationSpeed seconds
-	AnimationSpeed       float64 // no of frames to display per second in seconds
-	Looping              bool    // true if the animation should loop
+	FrameTimer           float64    // time in seconds that the current frame has been displayed for ie: if FrameTimer is x, then the current frame is displayed for x/AnimationSpeed seconds
+	AnimationSpeed       float64    // no of frames to display per second in seconds
+	Looping              bool       // true if the animation should loop
+	Frames               *FrameData // hitboxes, hurtboxes and events per frame, from assets/framedata.json
+}
+
+// SpriteRect is where frame of the animation sits on the sprite sheet
+func (a *Animation) SpriteRect(frame int) image.Rectangle {
+	x := frame * a.FrameWidth
+	y := a.SpriteSheetYPosition * frameHeight_minimum
+	return image.Rect(x, y, x+a.FrameWidth, y+a.FrameHeight)
+}
+
+// PlayerFrameData is the player's section of the frame data file
+const PlayerFrameData = "player"
+
+// PlayerAnimationNames are the names the frame data file uses for the player animations
+var PlayerAnimationNames = map[string]int{
+	"idle":              Idle,
+	"moving":            Moving,
+	"running":           Running,
+	"jumping":           Jumping,
+	"falling":           Falling,
+	"landing":           Landing,
+	"smugFace":          SmugFace,
+	"weakAttack":        WeakAttack,
+	"strongAttack":      StrongAttack,
+	"specialAttack1":    SpecialAttack1,
+	"specialAttack2":    SpecialAttack2,
+	"specialAttack3":    SpecialAttack3,
+	"specialAttack4":    SpecialAttack4,
+	"weakAttackInAir":   WeakAttackInAir,
+	"strongAttackInAir": StrongAttackInAir,
+	"damaged":           Damaged,
+	"dead":              Dead,
+	"defense":           Defense,
+	"usePotion":         UsePotion,
+	"dashing":           Dashing,
+	"wallSliding":       WallSliding,
+	"wallJumping":       WallJumping,
+	"swimming":          Swimming,
 }
 
 // image dimensions
@@ -57,11 +94,10 @@ const (
 	frameHeight_medium  = 120 // this frame is  not used
 	// frameHeight_large   = 160 // this frame is used
 	// frameHeight_maximum = 200 // this frame is currently not used
-
-	playerSpriteSheetPath = "../assets/GideonGraves.png"
 )
 
-func InitPlayerAnimations() map[int]*Animation {
+// InitPlayerAnimations builds the player animations with their frame data
+func InitPlayerAnimations() (map[int]*Animation, error) {
 	animations := make(map[int]*Animation)
 	animations[Idle] = &Animation{
 		CurrentState:         PlayerStateIdle,
@@ -239,6 +275,7 @@ func InitPlayerAnimations() map[int]*Animation {
 	}
 
 	// animation yet to make
+	// plays once over the hurt stun (HurtStunMs)
 	animations[Damaged] = &Animation{
 		CurrentState:         PlayerStateDamaged,
 		SpriteSheetYPosition: 7,
@@ -246,9 +283,10 @@ func InitPlayerAnimations() map[int]*Animation {
 		FrameWidth:           frameWidth_small,
 		FrameHeight:          frameHeight_small,
 		FrameTimer:           0,
-		AnimationSpeed:       1,
+		AnimationSpeed:       20,
 		Looping:              false,
 	}
+	// plays once before the game over screen (game.RespawnDelay)
 	animations[Dead] = &Animation{
 		CurrentState:         PlayerStateDead,
 		SpriteSheetYPosition: 8,
@@ -256,10 +294,12 @@ func InitPlayerAnimations() map[int]*Animation {
 		FrameWidth:           frameWidth_small,
 		FrameHeight:          frameHeight_small,
 		FrameTimer:           0,
-		AnimationSpeed:       1,
+		AnimationSpeed:       4,
 		Looping:              false,
 	}
 
+	// animations yet to make
+	// raises the guard quickly, then holds it on the last frame
 	animations[Defense] = &Animation{
 		CurrentState:         PlayerStateDefense,
 		SpriteSheetYPosition: 10,
@@ -267,9 +307,10 @@ func InitPlayerAnimations() map[int]*Animation {
 		FrameWidth:           frameWidth_small,
 		FrameHeight:          frameHeight_small,
 		FrameTimer:           0,
-		AnimationSpeed:       1,
+		AnimationSpeed:       20,
 		Looping:              false,
 	}
+	// the potion heals when it ends
 	animations[UsePotion] = &Animation{
 		CurrentState:         PlayerStateUsePotion,
 		SpriteSheetYPosition: 11,
@@ -277,36 +318,112 @@ func InitPlayerAnimations() map[int]*Animation {
 		FrameWidth:           frameWidth_small,
 		FrameHeight:          frameHeight_small,
 		FrameTimer:           0,
-		AnimationSpeed:       1,
+		AnimationSpeed:       6,
 		Looping:              false,
 	}
 
-	return animations
+	// animation yet to make, borrows the run cycle played fast
+	animations[Dashing] = &Animation{
+		CurrentState:         PlayerStateDash,
+		SpriteSheetYPosition: 4,
+		TotalFrames:          8,
+		AnimStartFrame:       0,
+		FrameWidth:           frameWidth_small,
+		FrameHeight:          frameHeight_small,
+		FrameTimer:           0,
+		AnimationSpeed:       30,
+		Looping:              true,
+	}
+	// animation yet to make, the fall pose held against the wall
+	animations[WallSliding] = &Animation{
+		CurrentState:         PlayerStateWallSlide,
+		SpriteSheetYPosition: 10,
+		TotalFrames:          1,
+		AnimStartFrame:       3,
+		FrameWidth:           frameWidth_small,
+		FrameHeight:          frameHeight_small,
+		FrameTimer:           0,
+		AnimationSpeed:       5,
+		Looping:              true,
+	}
+	animations[WallJumping] = &Animation{
+		CurrentState:         PlayerStateWallJump,
+		SpriteSheetYPosition: 10,
+		TotalFrames:          3,
+		AnimStartFrame:       0,
+		FrameWidth:           frameWidth_small,
+		FrameHeight:          frameHeight_small,
+		FrameTimer:           0,
+		AnimationSpeed:       10,
+		Looping:              false,
+	}
+	// animation yet to make, a slow walk cycle
+	animations[Swimming] = &Animation{
+		CurrentState:         PlayerStateSwim,
+		SpriteSheetYPosition: 2,
+		TotalFrames:          5,
+		AnimStartFrame:       0,
+		FrameWidth:           frameWidth_minimum,
+		FrameHeight:          frameHeight_small,
+		FrameTimer:           0,
+		AnimationSpeed:       4,
+		Looping:              true,
+	}
+
+	frameData, err := DefaultFrameData()
+	if err != nil {
+		return nil, err
+	}
+	err = AttachFrameData(PlayerFrameData, frameData[PlayerFrameData], PlayerAnimationNames,
+		func(key int) (int, bool) {
+			anim, ok := animations[key]
+			if !ok {
+				return 0, false
+			}
+			return anim.TotalFrames, true
+		},
+		func(key int, data *FrameData) { animations[key].Frames = data },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return animations, nil
 }
 
-func (player *PlayerRuntime) UpdateAnimation() {
-	// // update player animation
-	tps := float64(ebiten.TPS())
-	if tps <= 0 {
-		tps = 60
+// CurrentFrame is the frame data of the frame the player shows
+func (player *PlayerRuntime) CurrentFrame() Frame {
+	anim, ok := player.Animations[player.State.GetPlayerState()]
+	if !ok {
+		return Frame{}
 	}
+	return anim.Frames.At(player.CurrAnimFrame - anim.AnimStartFrame)
+}
 
+// Hurtboxes are where the player can be hurt on the frame it shows
+func (player *PlayerRuntime) Hurtboxes() []AABB {
+	return player.CurrentFrame().HurtboxesOn(player.GetBounds(), player.FlipX)
+}
+
+// UpdateAnimation advances the player animation by dt seconds
+func (player *PlayerRuntime) UpdateAnimation(dt float64) {
 	// here DT signifies the time in seconds between each frame of the animation means how long the current frame is displayed for
 	currState := player.State.GetPlayerState()
 	anim := player.Animations[currState]
+	player.FrameEvents = player.FrameEvents[:0]
 
 	if player.PreviousState.GetPlayerState() != player.State.GetPlayerState() {
 		player.CurrAnimFrame = anim.AnimStartFrame
+		player.FrameEvents = append(player.FrameEvents, player.CurrentFrame().Events...)
 	}
 
 	timePerFrame := 1.0 / anim.AnimationSpeed // time (in seconds)to display each frame
-	dt := 1.0 / tps                           // time (in seconds) between each frame
 	anim.FrameTimer += dt                     // add the time (in seconds) between each frame to the frame timer
 
 	// the for loop helps to keep the animation running at the correct speed
 	for anim.FrameTimer >= timePerFrame {
 		anim.FrameTimer -= timePerFrame
 
+		prevFrame := player.CurrAnimFrame
 		player.CurrAnimFrame++
 
 		// fmt.Println("player.CurrAnimFrame", player.CurrAnimFrame, anim.AnimStartFrame+anim.TotalFrames, currState)
@@ -319,62 +436,22 @@ func (player *PlayerRuntime) UpdateAnimation() {
 					player.State.IsSpecialAttack3() || player.State.IsSpecialAttack4() {
 					player.State.SetPlayerState(int(PlayerStateIdle))
 					player.CurrAnimFrame = 0
-				} else if player.State.IsWeakAttackInAir() || player.State.IsStrongAttackInAir() || player.State.IsJumping() {
+				} else if player.State.IsUsingPotion() {
+					player.drinkPotion()
+					player.State.SetPlayerState(int(PlayerStateIdle))
+					player.CurrAnimFrame = 0
+				} else if player.State.IsWeakAttackInAir() || player.State.IsStrongAttackInAir() || player.State.IsJumping() || player.State.IsWallJumping() {
 					player.State.SetPlayerState(int(PlayerStateFalling))
+				} else if player.State.IsDead() || player.State.IsDamaged() || player.State.IsDefense() {
+					// hold the last frame until respawn, until the hurt stun ends, or while guarding
+					player.CurrAnimFrame = anim.AnimStartFrame + anim.TotalFrames - 1
 				}
 			}
 		}
-	}
-}
-
-func (player *PlayerRuntime) DrawPlayerAnimation(screen *ebiten.Image) {
-
-	bounds := player.GetBounds()
-	// Adjust bounds for camera
-	// drawBoundsX := float32(bounds.X - player.Camera.Pos.X)
-	// drawBoundsY := float32(bounds.Y - player.Camera.Pos.Y)
-
-	// vector.StrokeRect(screen, drawBoundsX, drawBoundsY, float32(bounds.Width), float32(bounds.Height), 1, color.White, false)
-	// // draw ground sensor
-	// groundSensor := player.GetGroundSensor()
-	// vector.FillRect(screen, float32(groundSensor.X-player.Camera.Pos.X), float32(groundSensor.Y-player.Camera.Pos.Y), float32(groundSensor.Width), float32(groundSensor.Height), color.RGBA{255, 0, 0, 50}, false)
-
-	currState := player.State.GetPlayerState()
-	width := player.Animations[currState].FrameWidth
-	height := player.Animations[currState].FrameHeight
-	frameX := player.CurrAnimFrame * width
-	frameY := player.Animations[currState].SpriteSheetYPosition * frameHeight_minimum
-
-	rect := image.Rect(frameX, frameY, frameX+width, frameY+height)
-	subImage := player.img.SubImage(rect).(*ebiten.Image)
 
-	op := &ebiten.DrawImageOptions{}
-
-	// Scale factor to resize the player image
-	// Change this value to make the player smaller (e.g., 0.5) or larger (e.g., 2.0)
-
-	if player.FlipX {
-		// Flip horizontally
-		op.GeoM.Scale(-player.Scale, player.Scale)
-		// Translate back because flipping moves the image to the left of the axis
-		op.GeoM.Translate(float64(width)*player.Scale, 0)
-	} else {
-		op.GeoM.Scale(player.Scale, player.Scale)
+		// events fire as their frame comes up, and not for the frame an ending animation hands over to
+		if player.State.GetPlayerState() == currState && player.CurrAnimFrame != prevFrame {
+			player.FrameEvents = append(player.FrameEvents, player.CurrentFrame().Events...)
+		}
 	}
-
-	// Calculate draw position to center the sprite on the collision box
-	// Center horizontally: bounds.X + (bounds.Width - spriteWidth) / 2
-	drawX := bounds.X + (bounds.Width-float64(width)*player.Scale)/2
-
-	// Align bottom vertically: bounds.Y + (bounds.Height - spriteHeight)
-	drawY := bounds.Y + (bounds.Height - float64(height)*player.Scale)
-
-	// Apply Camera Offset
-	drawX -= player.Camera.Pos.X
-	drawY -= player.Camera.Pos.Y
-
-	// Move to the calculated position
-	op.GeoM.Translate(drawX, drawY)
-
-	screen.DrawImage(subImage, op)
 }
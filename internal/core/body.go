@@ -0,0 +1,346 @@
+package core
+
+import "math"
+
+// ---------------- kinematic body ----------------
+// KinematicBody is the moving box under the player and every enemy: where it
+// is, how fast it goes, how big it is and how it collides with the level.
+// Embed it and the Pos, Physics and GetBounds of the body are promoted.
+type KinematicBody struct {
+	Pos     Position
+	PrevPos Position // position at the previous simulation step (for interpolation)
+	Physics Physics
+
+	Width, Height float64
+	SensorDepth   float64 // how far below the feet the ground sensor reaches
+
+	OnGround   bool // landed on a platform during the last MoveY
+	NearGround bool // the ground sensor touched a platform during the last Move
+	WallDir    int  // side a wall touches the body on after the last Move, -1 left, +1 right, 0 none
+
+	Ground         TileType      // tile under the ground sensor, Empty in the air
+	GroundShape    PlatformShape // shape of that tile, eg: a slope
+	GroundPlatform *Platform     // the platform of that tile, nil in the air
+	Medium         TileType      // non-solid tile the body's center is in, eg: water, Empty in the open
+
+	DropThroughMs float64 // remaining ms one-way platforms are passed through
+}
+
+// wallProbe is how far beside the body the wall sensor reaches
+const wallProbe = 1.0
+
+func NewKinematicBody(pos Position, width, height, sensorDepth float64, physics Physics) KinematicBody {
+	return KinematicBody{
+		Pos:         pos,
+		PrevPos:     pos,
+		Physics:     physics,
+		Width:       width,
+		Height:      height,
+		SensorDepth: sensorDepth,
+	}
+}
+
+// GetBounds implements Collider so bodies can be tracked in the quadtree
+func (b *KinematicBody) GetBounds() AABB {
+	return AABB{X: b.Pos.X, Y: b.Pos.Y, Width: b.Width, Height: b.Height}
+}
+
+// GroundSensor returns the box just below the feet used to tell the body is
+// about to land before physics resolves
+func (b *KinematicBody) GroundSensor() AABB {
+	return AABB{X: b.Pos.X, Y: b.Pos.Y + b.Height, Width: b.Width, Height: b.SensorDepth}
+}
+
+// SteerX eases VelX toward targetVX by step. Above MaxSpeed and not sprinting,
+// the body bleeds speed back down to MaxSpeed at decX instead.
+func (b *KinematicBody) SteerX(targetVX, step, decX float64, sprinting bool) {
+	maxSpeed := b.Physics.MaxSpeed
+	if math.Abs(b.Physics.VelX) > maxSpeed && !sprinting {
+		// Decelerate from run speed to normal max speed
+		if b.Physics.VelX > 0 {
+			b.Physics.VelX = ReduceRight(b.Physics.VelX, maxSpeed, decX)
+		} else if b.Physics.VelX < 0 {
+			b.Physics.VelX = ReduceLeft(b.Physics.VelX, -maxSpeed, decX)
+		}
+		return
+	}
+	b.Physics.VelX = Approach(b.Physics.VelX, targetVX, step)
+}
+
+// ApplyGravity pulls the body down for one step of dt seconds
+func (b *KinematicBody) ApplyGravity(dt float64) {
+	dtUnits := 100.0 * dt // same scaling the physics constants are tuned for
+	b.Physics.VelY += b.Physics.GravityScale * dtUnits
+}
+
+// StandingOn is the platform the body stands on, nil in the air
+func (b *KinematicBody) StandingOn() *Platform {
+	p := b.GroundPlatform
+	if !b.OnGround || p == nil || math.Abs(b.Pos.Y+b.Height-p.FloorY(b.Pos.X+b.Width/2)) > 1 {
+		return nil
+	}
+	return p
+}
+
+// ride carries the body along with the moving platform it stood on last step.
+// The platform has moved already, a wall or ceiling in the way stops the body
+// and the platform goes on without it.
+func (b *KinematicBody) ride(qt *DynamicQuadtree) {
+	p := b.GroundPlatform
+	if !b.OnGround || p == nil || p.Motion == nil {
+		return
+	}
+	m := p.Motion
+	if math.Abs(b.Pos.Y+b.Height-(p.Y-m.DeltaY)) > 1 {
+		return // not standing on its top
+	}
+
+	if c, hit := SweepPlatforms(qt, b.GetBounds(), m.DeltaX, 0, false); hit {
+		b.Pos.X += m.DeltaX * c.Time
+	} else {
+		b.Pos.X += m.DeltaX
+	}
+
+	dy := p.Y - b.Height - b.Pos.Y // keep the feet on the top, even going down
+	if c, hit := SweepPlatforms(qt, b.GetBounds(), 0, dy, false); hit && dy < 0 {
+		b.Pos.Y += dy * c.Time
+		return
+	}
+	b.Pos.Y += dy
+}
+
+// OnSlope reports whether the body stands on a slope
+func (b *KinematicBody) OnSlope() bool {
+	return b.OnGround && b.GroundShape.IsSlope()
+}
+
+// OnOneWay reports whether the body stands on a one-way platform
+func (b *KinematicBody) OnOneWay() bool {
+	return b.OnGround && b.GroundShape == ShapeOneWay
+}
+
+// stepHeight is how far a body moving by dx may be lifted or lowered to stay
+// on a slope, half the body covers where the middle of the feet is off the tile
+func (b *KinematicBody) stepHeight(dx float64) float64 {
+	return b.Width/2 + math.Abs(dx) + 1
+}
+
+// MoveX moves the body by VelX*dt, stopping flush against the first wall in the way.
+// Walking off the top of a slope it steps up onto a ledge level with the slope.
+func (b *KinematicBody) MoveX(qt *DynamicQuadtree, dt float64) (Contact, bool) {
+	dx := b.Physics.VelX * dt
+	contact, hit := SweepPlatforms(qt, b.GetBounds(), dx, 0, false)
+	if hit && b.OnSlope() {
+		feet := b.Pos.Y + b.Height
+		if top := contactTop(contact, dx); top < feet && feet-top <= b.stepHeight(dx) {
+			b.Pos.Y = top - b.Height
+			contact, hit = SweepPlatforms(qt, b.GetBounds(), dx, 0, false)
+		}
+	}
+	if !hit {
+		b.Pos.X += dx
+		return contact, false
+	}
+
+	bounds := contact.Collider.GetBounds()
+	if contact.NormalX < 0 { // hit the left side of a wall
+		b.Pos.X = bounds.X - b.Width
+	} else { // hit the right side of a wall
+		b.Pos.X = bounds.X + bounds.Width
+	}
+	b.Physics.VelX = 0
+	return contact, true
+}
+
+// MoveY caps the fall speed and moves the body by VelY*dt, landing on floors
+// and bonking on ceilings
+func (b *KinematicBody) MoveY(qt *DynamicQuadtree, dt float64) (Contact, bool) {
+	if b.Physics.VelY > b.Physics.MaxFallSpeed {
+		b.Physics.VelY = b.Physics.MaxFallSpeed
+	}
+
+	b.OnGround = false
+	dy := b.Physics.VelY * dt
+	contact, hit := SweepPlatforms(qt, b.GetBounds(), 0, dy, b.DropThroughMs > 0)
+	if !hit {
+		b.Pos.Y += dy
+		return contact, false
+	}
+
+	bounds := contact.Collider.GetBounds()
+	if contact.NormalY < 0 { // landing
+		b.Pos.Y = bounds.Y - b.Height
+		b.OnGround = true
+	} else { // head bonk
+		b.Pos.Y = bounds.Y + bounds.Height
+	}
+	b.Physics.VelY = 0
+	return contact, true
+}
+
+// contactTop is the top of what a sideways move ran into
+func contactTop(c Contact, dx float64) float64 {
+	if p, ok := c.Collider.(*Platform); ok && p.IsSlope() {
+		return p.slopeFace(dx).Y
+	}
+	return c.Collider.GetBounds().Y
+}
+
+// snapToSlope keeps the body on the slope under the middle of its feet. It
+// lifts a body that sank into a slope, and pulls one that was standing last
+// step down onto a slope it is walking down, or onto the flat ground at the
+// foot of one. startFeet is where the bottom of the body was before the move.
+func (b *KinematicBody) snapToSlope(qt *DynamicQuadtree, startFeet, dx float64, wasGrounded, fromSlope bool) {
+	if qt == nil || b.Physics.VelY < 0 {
+		return
+	}
+	reach := b.stepHeight(dx)
+	below := 0.0 // how far under the feet a slope may be and still be snapped to
+	if wasGrounded && !b.OnGround {
+		below = reach
+	}
+
+	midX := b.Pos.X + b.Width/2
+	feet := b.Pos.Y + b.Height
+	top := math.Min(startFeet, feet) - reach
+	area := AABB{X: midX, Y: top, Width: 1, Height: math.Max(startFeet, feet) + below - top}
+
+	var ground *Platform
+	floor := math.Inf(1)
+	for _, obj := range qt.Retrieve(area) {
+		p, ok := solidPlatform(obj)
+		if !ok || midX < p.X || midX >= p.X+p.Width {
+			continue
+		}
+		// a surface already well above the feet is the body walking under it
+		y := p.FloorY(midX)
+		if y < startFeet-reach || y > feet+below || y >= floor {
+			continue
+		}
+		// flat ground is only pulled down onto walking off the bottom of a slope
+		if !p.IsSlope() && (!fromSlope || y < feet) {
+			continue
+		}
+		ground, floor = p, y
+	}
+	if ground == nil {
+		return
+	}
+	b.Pos.Y = floor - b.Height
+	b.Physics.VelY = 0
+	b.OnGround = true
+}
+
+// SenseGround checks the ground sensor against the solid platforms in qt and
+// remembers which tile it touches, the highest one under the middle of the feet if it can.
+// One-way platforms only count while the body is above them and not dropping through.
+func (b *KinematicBody) SenseGround(qt *DynamicQuadtree) bool {
+	b.NearGround = false
+	b.Ground = Empty
+	b.GroundShape = ShapeSolid
+	b.GroundPlatform = nil
+	if qt == nil {
+		return false
+	}
+	sensor := b.GroundSensor()
+	midX := b.Pos.X + b.Width/2
+	feet := b.Pos.Y + b.Height
+	floor := math.Inf(1)
+	for _, obj := range qt.Retrieve(sensor) {
+		p, ok := solidPlatform(obj)
+		if !ok || !sensor.Intersects(p.GetBounds()) {
+			continue
+		}
+		if p.IsOneWay() && (b.DropThroughMs > 0 || feet > p.Y+sweepSkin) {
+			continue
+		}
+		under := midX >= p.X && midX < p.X+p.Width
+		if !b.NearGround || (under && p.FloorY(midX) < floor) {
+			b.Ground = p.TileInfo.TileType
+			b.GroundShape = p.Shape
+			b.GroundPlatform = p
+			if under {
+				floor = p.FloorY(midX)
+			}
+		}
+		b.NearGround = true
+	}
+	return b.NearGround
+}
+
+// SenseMedium finds the non-solid tile the middle of the body is in
+func (b *KinematicBody) SenseMedium(qt *DynamicQuadtree) TileType {
+	b.Medium = Empty
+	if qt == nil {
+		return Empty
+	}
+	midX, midY := b.Pos.X+b.Width/2, b.Pos.Y+b.Height/2
+	for _, obj := range qt.Retrieve(AABB{X: midX, Y: midY, Width: 1, Height: 1}) {
+		p, ok := obj.(*Platform)
+		if ok && !p.IsSolid() && p.GetBounds().Contains(midX, midY) {
+			b.Medium = p.TileInfo.TileType
+			break
+		}
+	}
+	return b.Medium
+}
+
+// GroundMaterial is the material under the feet, DefaultMaterial in the air
+func (b *KinematicBody) GroundMaterial() Material {
+	if !b.NearGround {
+		return DefaultMaterial
+	}
+	return MaterialOf(b.Ground)
+}
+
+// InWater reports whether the body is in a swimmable tile
+func (b *KinematicBody) InWater() bool {
+	return MaterialOf(b.Medium).Swimmable
+}
+
+// SenseWall checks for a wall right next to either side of the body,
+// one-way platforms and slopes are never walls
+func (b *KinematicBody) SenseWall(qt *DynamicQuadtree) int {
+	b.WallDir = 0
+	if qt == nil {
+		return 0
+	}
+	// keep the probes off the floor and ceiling the body may be touching
+	left := AABB{X: b.Pos.X - wallProbe, Y: b.Pos.Y + 1, Width: wallProbe, Height: b.Height - 2}
+	right := AABB{X: b.Pos.X + b.Width, Y: b.Pos.Y + 1, Width: wallProbe, Height: b.Height - 2}
+	area := AABB{X: left.X, Y: left.Y, Width: b.Width + 2*wallProbe, Height: left.Height}
+	for _, obj := range qt.Retrieve(area) {
+		p, ok := solidPlatform(obj)
+		if !ok || p.IsOneWay() || p.IsSlope() {
+			continue
+		}
+		bounds := p.GetBounds()
+		if left.Intersects(bounds) {
+			b.WallDir = -1
+			break
+		}
+		if right.Intersects(bounds) {
+			b.WallDir = 1
+			break
+		}
+	}
+	return b.WallDir
+}
+
+// Move runs one step of movement: along with the platform under the body,
+// then X, then Y, then slopes, then the sensors.
+// It returns the wall the body ran into, if any.
+func (b *KinematicBody) Move(qt *DynamicQuadtree, dt float64) (Contact, bool) {
+	b.DropThroughMs = math.Max(0, b.DropThroughMs-dt*1000)
+	b.ride(qt)
+	wasGrounded, fromSlope := b.OnGround, b.OnSlope()
+	startX, startFeet := b.Pos.X, b.Pos.Y+b.Height
+
+	wall, hitWall := b.MoveX(qt, dt)
+	b.MoveY(qt, dt)
+	b.snapToSlope(qt, startFeet, b.Pos.X-startX, wasGrounded, fromSlope)
+	b.SenseGround(qt)
+	b.SenseWall(qt)
+	b.SenseMedium(qt)
+	return wall, hitWall
+}
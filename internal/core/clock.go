@@ -0,0 +1,66 @@
+package core
+
+// ------------------------ simulation clock constants ------------------------
+const (
+	TickRate         = 60 // simulation steps per second
+	MaxStepsPerFrame = 5  // cap on catch-up steps so a long frame can not spiral
+)
+
+// SimClock is a fixed-timestep accumulator. The caller feeds it real frame
+// time, runs one simulation step per tick it hands back, and uses Alpha to
+// interpolate rendering between the previous and the current step.
+type SimClock struct {
+	Step        float64 // length of one simulation step in seconds
+	TimeScale   float64 // 1 = real time, 0.5 = slow motion, 0 = frozen
+	Accumulator float64 // unsimulated time carried over to the next frame
+	Alpha       float64 // fraction of a step left in the accumulator (0-1), used for interpolation
+	Ticks       uint64  // total simulation steps taken
+}
+
+// NewSimClock creates a clock stepping tickRate times per second
+func NewSimClock(tickRate int) *SimClock {
+	if tickRate <= 0 {
+		tickRate = TickRate
+	}
+	return &SimClock{
+		Step:      1.0 / float64(tickRate),
+		TimeScale: 1.0,
+	}
+}
+
+// Advance adds frameDt seconds of real time and returns how many fixed steps
+// the caller has to run this frame
+func (c *SimClock) Advance(frameDt float64) int {
+	if frameDt > 0 {
+		c.Accumulator += frameDt * c.TimeScale
+	}
+
+	steps := 0
+	for c.Accumulator >= c.Step && steps < MaxStepsPerFrame {
+		c.Accumulator -= c.Step
+		steps++
+	}
+
+	// drop whatever we could not catch up on instead of carrying it forever
+	if steps == MaxStepsPerFrame && c.Accumulator >= c.Step {
+		c.Accumulator = 0
+	}
+
+	c.Ticks += uint64(steps)
+	c.Alpha = c.Accumulator / c.Step
+	return steps
+}
+
+// Reset drops any accumulated time, eg: after a pause or a level load
+func (c *SimClock) Reset() {
+	c.Accumulator = 0
+	c.Alpha = 0
+}
+
+// Lerp interpolates between the previous and the current position
+func Lerp(prev, curr Position, alpha float64) Position {
+	return Position{
+		X: prev.X + (curr.X-prev.X)*alpha,
+		Y: prev.Y + (curr.Y-prev.Y)*alpha,
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimClockAdvance(t *testing.T) {
+	step := 1.0 / TickRate
+	tests := []struct {
+		name      string
+		tickRate  int
+		timeScale float64
+		frames    []float64 // frame times fed in, in seconds
+		wantSteps int       // steps handed back by the last frame
+		wantTicks uint64
+		wantAlpha float64
+	}{
+		{"one frame, one step", TickRate, 1, []float64{step}, 1, 1, 0},
+		{"half a step waits", TickRate, 1, []float64{step / 2}, 0, 0, 0.5},
+		{"two halves make a step", TickRate, 1, []float64{step / 2, step / 2}, 1, 1, 0},
+		{"a 30 fps frame runs two", TickRate, 1, []float64{2 * step}, 2, 2, 0},
+		{"remainder carries into alpha", TickRate, 1, []float64{2.25 * step}, 2, 2, 0.25},
+		{"long frame is capped and dropped", TickRate, 1, []float64{1}, MaxStepsPerFrame, MaxStepsPerFrame, 0},
+		{"slow motion", TickRate, 0.5, []float64{step, step}, 1, 1, 0},
+		{"frozen", TickRate, 0, []float64{1}, 0, 0, 0},
+		{"negative frame is ignored", TickRate, 1, []float64{-1}, 0, 0, 0},
+		{"30 tps clock", 30, 1, []float64{step}, 0, 0, 0.5},
+		{"zero tick rate falls back", 0, 1, []float64{step}, 1, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSimClock(tt.tickRate)
+			c.TimeScale = tt.timeScale
+			steps := 0
+			for _, dt := range tt.frames {
+				steps = c.Advance(dt)
+			}
+			if steps != tt.wantSteps {
+				t.Errorf("steps = %d, want %d", steps, tt.wantSteps)
+			}
+			if c.Ticks != tt.wantTicks {
+				t.Errorf("ticks = %d, want %d", c.Ticks, tt.wantTicks)
+			}
+			if math.Abs(c.Alpha-tt.wantAlpha) > 1e-9 {
+				t.Errorf("alpha = %v, want %v", c.Alpha, tt.wantAlpha)
+			}
+		})
+	}
+}
+
+// TestSimClockAlphaRange feeds uneven frame times, alpha must always stay in [0, 1)
+func TestSimClockAlphaRange(t *testing.T) {
+	c := NewSimClock(TickRate)
+	frames := []float64{0.001, 0.016, 0.017, 0.033, 0.0167, 0.25, 0, 0.009, 1.0 / 144, 1.0 / 30}
+	for i := 0; i < 1000; i++ {
+		c.Advance(frames[i%len(frames)])
+		if c.Alpha < 0 || c.Alpha >= 1 {
+			t.Fatalf("frame %d: alpha = %v, want it in [0, 1)", i, c.Alpha)
+		}
+	}
+}
+
+func TestSimClockReset(t *testing.T) {
+	c := NewSimClock(TickRate)
+	c.Advance(1.5 / TickRate)
+	c.Reset()
+	if c.Accumulator != 0 || c.Alpha != 0 {
+		t.Fatalf("accumulator %v, alpha %v after reset, want both 0", c.Accumulator, c.Alpha)
+	}
+	if steps := c.Advance(0.5 / TickRate); steps != 0 {
+		t.Fatalf("reset kept time, %d steps from half a step", steps)
+	}
+}
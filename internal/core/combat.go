@@ -1 +1,29 @@
 package core
+
+// TakeDamage lowers health by amount, never below zero, and returns the damage actually taken
+func (c *Combat) TakeDamage(amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	if amount > c.Health {
+		amount = c.Health
+	}
+	c.Health -= amount
+	return amount
+}
+
+// Heal raises health by amount, never above MaxHealth, and returns what was healed
+func (c *Combat) Heal(amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	if c.Health+amount > c.MaxHealth {
+		amount = c.MaxHealth - c.Health
+	}
+	c.Health += amount
+	return amount
+}
+
+func (c *Combat) IsDead() bool {
+	return c.Health <= 0
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"player/assets"
+)
+
+// ------------------------ combo constants ------------------------
+// the chains live in assets/combos.json, the frames an attack can be
+// cancelled into the next step on are its cancel range in assets/framedata.json
+const (
+	ComboBufferMs = 250  // an attack pressed this long before the cancel window still chains
+	ComboResetMs  = 1500 // the combo counter drops after this long without a hit
+)
+
+// ---------------- combo definitions ----------------
+// ComboDef is one attack chain, its steps are player animation names and
+// each step is asked for with the button of its attack
+type ComboDef struct {
+	Name  string   `json:"-"`
+	Steps []string `json:"steps"`
+	Bonus int      `json:"bonus"` // score for landing the last step
+
+	states []PlayerStateType
+}
+
+// ParseCombos reads the contents of a combo file: combo name -> definition.
+// They come back sorted by name, the first chain that fits a press wins.
+func ParseCombos(data []byte) ([]ComboDef, error) {
+	var set map[string]ComboDef
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("combos: %w", err)
+	}
+
+	defs := make([]ComboDef, 0, len(set))
+	for name, def := range set {
+		def.Name = name
+		if len(def.Steps) < 2 {
+			return nil, fmt.Errorf("combo %s: a chain needs at least 2 steps", name)
+		}
+		for _, step := range def.Steps {
+			anim, ok := PlayerAnimationNames[step]
+			if !ok || comboButton(PlayerStateType(anim), Skills{}) == nil {
+				return nil, fmt.Errorf("combo %s: %q is not an attack", name, step)
+			}
+			def.states = append(def.states, PlayerStateType(anim))
+		}
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// LoadCombos reads a combo file from disk
+func LoadCombos(path string) ([]ComboDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := ParseCombos(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return defs, nil
+}
+
+// DefaultCombos are the chains the game ships with, embedded from assets/combos.json
+func DefaultCombos() ([]ComboDef, error) {
+	return ParseCombos(assets.Combos)
+}
+
+// comboButton is the button that asks for the attack of state, nil if it
+// isn't an attack a chain can go through
+func comboButton(state PlayerStateType, skills Skills) *bool {
+	switch state {
+	case PlayerStateWeakAttack, PlayerStateWeakAttackInAir:
+		return &skills.WeakAttack
+	case PlayerStateStrongAttack, PlayerStateStrongAttackInAir:
+		return &skills.StrongAttack
+	case PlayerStateSpecialAttack1:
+		return &skills.SpecialAttack1
+	case PlayerStateSpecialAttack2:
+		return &skills.SpecialAttack2
+	case PlayerStateSpecialAttack3:
+		return &skills.SpecialAttack3
+	case PlayerStateSpecialAttack4:
+		return &skills.SpecialAttack4
+	}
+	return nil
+}
+
+// pressed reports whether the button of state went down this step
+func pressed(state PlayerStateType, now, before Skills) bool {
+	down, was := comboButton(state, now), comboButton(state, before)
+	return down != nil && *down && !*was
+}
+
+// follows reports whether chain is where def starts
+func (def *ComboDef) follows(chain []PlayerStateType) bool {
+	if len(chain) > len(def.states) {
+		return false
+	}
+	for i, state := range chain {
+		if def.states[i] != state {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------- combo ----------------
+// Combo is the player's chain of attacks and its hit counter
+type Combo struct {
+	Chain    []PlayerStateType // attacks of the chain so far, the current one last
+	Queued   PlayerStateType   // next step asked for, it waits for the cancel window
+	BufferMs float64           // remaining ms the queued step waits, 0 is nothing queued
+	Hits     int               // hits landed without a break, the combo counter for scoring
+	SinceHit float64           // ms since the last hit
+
+	finisherBonus int    // earned by the first hit of the last step of a chain
+	held          Skills // buttons down last step, a step takes a fresh press
+}
+
+// Break drops the combo counter and the chain, eg: when the player gets hit
+func (c *Combo) Break() {
+	c.Chain = c.Chain[:0]
+	c.BufferMs = 0
+	c.Hits = 0
+	c.SinceHit = 0
+	c.finisherBonus = 0
+}
+
+// updateCombo chains the attacks. A fresh press during an attack queues the
+// step of the chain that matches it, and the queued step cuts the attack
+// short as soon as the attack is in its cancel window.
+func (player *PlayerRuntime) updateCombo(skills Skills, dt float64) {
+	c := &player.Combo
+	pressedBefore := c.held
+	c.held = skills
+
+	c.SinceHit += dt * 1000
+	if c.SinceHit >= ComboResetMs {
+		c.Hits = 0
+	}
+	c.BufferMs = max(0, c.BufferMs-dt*1000)
+
+	atk := player.attack()
+	if atk == nil {
+		c.Chain = c.Chain[:0]
+		c.BufferMs = 0
+		c.finisherBonus = 0
+		return
+	}
+	curr := PlayerStateType(player.State.GetPlayerState())
+	if curr != player.PreviousState.CurrentState {
+		c.Chain = append(c.Chain[:0], curr) // an attack started outside a chain opens one
+		c.BufferMs = 0
+		c.finisherBonus = 0
+	}
+
+	if next, ok := player.nextComboStep(skills, pressedBefore); ok {
+		c.Queued = next
+		c.BufferMs = ComboBufferMs
+	}
+	if c.BufferMs > 0 && atk.InCancelWindow(player.CurrAnimFrame-player.Animations[int(curr)].AnimStartFrame) {
+		c.BufferMs = 0
+		player.startComboStep(c.Queued)
+	}
+}
+
+// nextComboStep is the step a fresh press asks for after the chain so far
+func (player *PlayerRuntime) nextComboStep(skills, before Skills) (PlayerStateType, bool) {
+	chain := player.Combo.Chain
+	for i := range player.Combos {
+		def := &player.Combos[i]
+		if len(def.states) <= len(chain) || !def.follows(chain) {
+			continue
+		}
+		if next := def.states[len(chain)]; pressed(next, skills, before) {
+			return next, true
+		}
+	}
+	return 0, false
+}
+
+// startComboStep goes into the next step of the chain. A step of the same
+// attack plays it again from the top as a new swing.
+func (player *PlayerRuntime) startComboStep(next PlayerStateType) {
+	curr := PlayerStateType(player.State.GetPlayerState())
+	if !player.startAttack(next) {
+		return
+	}
+	if next == curr {
+		anim := player.Animations[int(next)]
+		player.CurrAnimFrame = anim.AnimStartFrame
+		anim.FrameTimer = 0
+		player.Swing++
+	}
+
+	c := &player.Combo
+	c.Chain = append(c.Chain, next)
+	for i := range player.Combos {
+		def := &player.Combos[i]
+		if len(def.states) == len(c.Chain) && def.follows(c.Chain) {
+			c.finisherBonus = def.Bonus
+			break
+		}
+	}
+}
+
+// comboHit counts a hit of swing on the combo counter, and returns the counter
+// and the bonus of the chain it finished, if it did. Only the player's own
+// swings finish chains, its projectiles just count.
+func (player *PlayerRuntime) comboHit(swing int) (hits, bonus int) {
+	if player == nil {
+		return 0, 0
+	}
+	c := &player.Combo
+	c.Hits++
+	c.SinceHit = 0
+	if swing == player.Swing && c.finisherBonus > 0 {
+		bonus, c.finisherBonus = c.finisherBonus, 0
+	}
+	return c.Hits, bonus
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// attackTick steps the player one tick with skills down, the way the world
+// does, and reports whether a combo step started on it
+func attackTick(t *testing.T, player *PlayerRuntime, qt *DynamicQuadtree, skills Skills) bool {
+	t.Helper()
+	dt := 1.0 / TickRate
+	chain := len(player.Combo.Chain)
+	frame := player.CurrAnimFrame
+	atk := player.attack()
+
+	UpdatePlayer(player, &InputState{Skills: skills}, qt, dt)
+	player.UpdateAnimation(dt)
+
+	stepped := len(player.Combo.Chain) > chain && chain > 0
+	if stepped && !atk.InCancelWindow(frame) {
+		t.Fatalf("combo step started on frame %d, outside the cancel window", frame)
+	}
+	return stepped
+}
+
+func TestComboCancelWindow(t *testing.T) {
+	weak := Skills{WeakAttack: true}
+	bufferTicks := ComboBufferMs * TickRate / 1000
+	tests := []struct {
+		name      string
+		pressTick int // ticks into the first swing the attack is pressed again
+		wantChain bool
+	}{
+		{"pressed in the window", 32, true},
+		{"buffered before the window", 32 - bufferTicks/2, true},
+		{"pressed too early", 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player, qt := groundedPlayer(t, Rock)
+			attackTick(t, player, qt, weak)
+			if !player.State.IsWeakAttack() {
+				t.Fatal("no weak attack on the press")
+			}
+			swing := player.Swing
+
+			chained := false
+			for i := 1; i < 2*TickRate && !chained; i++ {
+				chained = attackTick(t, player, qt, Skills{WeakAttack: i == tt.pressTick})
+			}
+			if chained != tt.wantChain {
+				t.Fatalf("chained = %v, want %v", chained, tt.wantChain)
+			}
+			if !chained {
+				return
+			}
+			if !player.State.IsWeakAttack() || player.Swing != swing+1 {
+				t.Fatalf("state %v swing %d after the chain, want the weak attack again as swing %d", player.State.CurrentState, player.Swing, swing+1)
+			}
+			if player.CurrAnimFrame != player.Animations[WeakAttack].AnimStartFrame {
+				t.Fatalf("second swing starts on frame %d, want the first", player.CurrAnimFrame)
+			}
+		})
+	}
+}
+
+func TestComboHeldButtonDoesNotChain(t *testing.T) {
+	player, qt := groundedPlayer(t, Rock)
+	weak := Skills{WeakAttack: true}
+	attackTick(t, player, qt, weak)
+	for i := 0; i < TickRate/2; i++ {
+		if attackTick(t, player, qt, weak) {
+			t.Fatal("holding the button chained, a step takes a fresh press")
+		}
+	}
+}
+
+func TestComboFinisher(t *testing.T) {
+	player, qt := groundedPlayer(t, Rock)
+	steps := []Skills{{WeakAttack: true}, {WeakAttack: true}, {StrongAttack: true}}
+
+	attackTick(t, player, qt, steps[0])
+	for _, press := range steps[1:] {
+		chained := false
+		for i := 0; i < 2*TickRate && !chained; i++ {
+			// tap the button every other tick until the chain takes it
+			skills := Skills{}
+			if i%2 == 0 {
+				skills = press
+			}
+			chained = attackTick(t, player, qt, skills)
+		}
+		if !chained {
+			t.Fatalf("chain stuck at %v", player.Combo.Chain)
+		}
+	}
+	if !player.State.IsStrongAttack() {
+		t.Fatalf("state %v at the end of the chain, want the strong attack", player.State.CurrentState)
+	}
+
+	// the first hit of the last step pays the bonus, once
+	if _, bonus := player.comboHit(player.Swing); bonus != 150 {
+		t.Fatalf("finisher bonus %d, want 150", bonus)
+	}
+	if _, bonus := player.comboHit(player.Swing); bonus != 0 {
+		t.Fatalf("second hit of the finisher paid %d again", bonus)
+	}
+}
+
+func TestParseCombosErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"one step", `{"jab": {"steps": ["weakAttack"]}}`, "at least 2 steps"},
+		{"not an attack", `{"hop": {"steps": ["weakAttack", "jumping"]}}`, "not an attack"},
+		{"unknown step", `{"x": {"steps": ["weakAttack", "kick"]}}`, "not an attack"},
+		{"not json", `steps`, "combos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCombos([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
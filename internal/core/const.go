@@ -1,8 +1,6 @@
 // internal/core/player_runtime.go
 package core
 
-import "github.com/hajimehoshi/ebiten/v2"
-
 // ========================= player runtime =========================
 type Physics struct {
 	VelX, VelY   float64 // current velocity of the object
@@ -16,6 +14,13 @@ type Physics struct {
 	TerminalVelY float64 // maximum falling speed
 	CoyoteMs     float64 // remaining ms of coyote time (extra jump time after leaving a platform)
 	AirJumpsLeft int     // number of air jumps left
+
+	// jump tuning, per character
+	CoyoteTimeMs   float64 // how long after leaving a platform a jump still counts as grounded
+	JumpBufferMs   float64 // how long before landing a jump press is remembered
+	JumpBufferLeft float64 // remaining ms of the buffered jump press
+	JumpCutScale   float64 // share of JumpForce kept when jump is released early (1 = no short hops)
+	MaxAirJumps    int     // air jumps given back on landing
 }
 
 type Combat struct {
@@ -24,6 +29,8 @@ type Combat struct {
 	MaxHealth float64 // Max health
 	Power     float64 // Power
 	MaxPower  float64 // Max Power
+
+	PowerRegen float64 `json:"-"` // power per second that comes back on its own, tuning so saves leave it out
 }
 
 // ---------------- direction ----------------
@@ -37,7 +44,7 @@ type Skills struct {
 	WeakAttack     bool
 	StrongAttack   bool
 	Defense        bool
-	UsePotion      bool
+	UsePotion      bool // just pressed, a potion takes a fresh press
 	SpecialAttack1 bool
 	SpecialAttack2 bool
 	SpecialAttack3 bool
@@ -52,50 +59,66 @@ type InputState struct {
 	DashJustPressed bool // true if the dash button was just pressed
 	RunJustPressed  bool // true if the run button was just pressed
 	Menu            bool // true if the menu button was just pressed
+	Confirm         bool // true if the confirm button was just pressed (menus)
+	QuickSave       bool // true if the quick save button was just pressed (not recorded in replays)
+	QuickLoad       bool // true if the quick load button was just pressed (not recorded in replays)
 	SmugFace        bool // true if the smug face button was just pressed
+	Respawn         bool // true if retry was just picked on the game over screen
 	Skills          Skills
 }
 
+// ConsumeEdges clears the just-pressed inputs once a simulation step has seen
+// them, so a frame that runs several steps does not act on one press twice
+func (in *InputState) ConsumeEdges() {
+	in.JumpJustPressed = false
+	in.DashJustPressed = false
+	in.Skills.UsePotion = false
+	in.Respawn = false
+}
+
 // ---------------- position ----------------
 type Position struct{ X, Y float64 } // position of the player in the world
 
 // ---------------- camera ----------------
 type Camera struct {
-	Pos  Position
-	Zoom float64
+	Pos     Position
+	PrevPos Position // camera position at the previous simulation step (for interpolation)
+	Zoom    float64
 }
 
 // ---------------- player runtime ----------------
 type PlayerRuntime struct {
-	img *ebiten.Image
 	// player state and animations
 	State         PlayerState
 	PreviousState PlayerState
 	Animations    map[int]*Animation
-	CurrAnimFrame int // current frame of the current animation
+	CurrAnimFrame int          // current frame of the current animation
+	FrameEvents   []FrameEvent // events of the frames the last animation update went through
 
 	// position and physics
-	FlipX   bool    // true if the player is facing left
-	Scale   float64 // scale of the player image
-	Pos     Position
-	Physics Physics
+	FlipX bool    // true if the player is facing left
+	Scale float64 // scale of the player image
+	KinematicBody
+
+	// dash, hurt stun and i-frames
+	Dash           Dash
+	HurtMs         float64 // remaining ms of the hurt stun after a hit
+	Swing          int     // counts attacks started, a target is hurt once per swing
+	InvulnerableMs float64 // remaining ms enemy attacks pass through the player
+	GuardMs        float64 // how long the guard has been up, hits in the first ParryWindowMs are parried
+	PowerDeniedMs  float64 // remaining ms of the flash after an attack was refused for lack of power
+	Combo          Combo
+	Combos         []ComboDef // attack chains the player knows
+	Statuses       Statuses   // burning, frozen, poisoned or stunned
+	Wall           WallMove
 
-	// combat and checkpoint
+	// combat, inventory and checkpoint
 	Combat       Combat
+	Potions      Potions
 	CheckpointID string
 	Camera       Camera
-	Grounded     bool // true if the player is touching the ground
 }
 
-// ---------------- game state ----------------
-type GameState int
-
-const (
-	ModeMenu GameState = iota
-	ModePlaying
-	ModeGameOver
-)
-
 // ---------------- genric pair types ----------------
 type Pair[T, U any] struct {
 	First  T
@@ -0,0 +1,84 @@
+package core
+
+import "math"
+
+// ------------------------ damage constants ------------------------
+const (
+	HurtStunMs    = 300  // input is ignored this long after a hit
+	HurtIFramesMs = 1000 // invulnerable this long after a hit
+	KnockbackX    = 350  // push away from the attacker
+	KnockbackY    = 300  // and up off the ground
+)
+
+// ---------------- hit ----------------
+// Hit is one attack landing on someone
+type Hit struct {
+	Damage     float64
+	SourceX    float64 // world X of the attacker's center, the victim is knocked away from it
+	KnockbackX float64
+	KnockbackY float64
+	Status     StatusKind // effect it leaves on the victim, empty for none
+}
+
+// HitResult is what became of a hit
+type HitResult int
+
+const (
+	HitMissed  HitResult = iota // invulnerable or already dead, nothing happened
+	HitLanded                   // damage, knockback and the hurt state
+	HitBlocked                  // taken on the guard, only part of the damage
+	HitParried                  // caught in the parry window, no damage and the attacker staggers
+)
+
+// NewHit is a hit with the default knockback
+func NewHit(damage, sourceX float64) Hit {
+	return Hit{Damage: damage, SourceX: sourceX, KnockbackX: KnockbackX, KnockbackY: KnockbackY}
+}
+
+// TakeHit applies damage, knockback and the hurt state with its i-frames.
+// Nothing happens while the player is invulnerable or already dead, and a
+// raised guard takes hits from the front. Health running out kills the player.
+func (player *PlayerRuntime) TakeHit(hit Hit) HitResult {
+	if player.State.IsDead() || player.IsInvulnerable() {
+		return HitMissed
+	}
+	if player.State.IsDefense() && player.faces(hit.SourceX) {
+		return player.guard(hit)
+	}
+	player.Combat.TakeDamage(hit.Damage)
+	player.Combo.Break()
+	player.Statuses.Apply(hit.Status)
+	if player.Combat.IsDead() {
+		player.Die()
+		return HitLanded
+	}
+
+	dir := 1.0
+	if player.Pos.X+player.Width/2 < hit.SourceX {
+		dir = -1
+	}
+	player.Physics.VelX = dir * hit.KnockbackX
+	player.Physics.VelY = -hit.KnockbackY
+	player.FlipX = dir > 0 // face the attacker
+
+	// a hit cuts a dash or a wall jump short
+	player.Dash.TimeLeftMs = 0
+	player.Wall.LockLeftMs = 0
+
+	player.HurtMs = HurtStunMs
+	player.InvulnerableMs = math.Max(player.InvulnerableMs, HurtIFramesMs)
+	player.State.SetPlayerState(int(PlayerStateDamaged))
+	return HitLanded
+}
+
+// recoverFromHurt ends the hurt state once the stun runs out
+func (player *PlayerRuntime) recoverFromHurt() {
+	if !player.State.IsDamaged() || player.HurtMs > 0 {
+		return
+	}
+	if player.OnGround {
+		player.State.SetPlayerState(int(PlayerStateIdle))
+	} else {
+		player.State.SetPlayerState(int(PlayerStateFalling))
+	}
+}
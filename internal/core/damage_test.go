@@ -0,0 +1,91 @@
+package core
+
+import "testing"
+
+func TestTakeHitKnockback(t *testing.T) {
+	tests := []struct {
+		name      string
+		sourceX   float64
+		wantVelX  float64
+		wantFlipX bool
+	}{
+		{"hit from the left", 0, KnockbackX, true},
+		{"hit from the right", 1000, -KnockbackX, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player, _ := groundedPlayer(t, Rock)
+			health := player.Combat.Health
+
+			if res := player.TakeHit(NewHit(10, tt.sourceX)); res != HitLanded {
+				t.Fatalf("result %v, want landed", res)
+			}
+			if player.Combat.Health != health-10 {
+				t.Fatalf("health %v, want %v", player.Combat.Health, health-10)
+			}
+			if player.Physics.VelX != tt.wantVelX || player.Physics.VelY != -KnockbackY {
+				t.Fatalf("knockback (%.2f, %.2f), want (%v, %v)", player.Physics.VelX, player.Physics.VelY, tt.wantVelX, -KnockbackY)
+			}
+			if player.FlipX != tt.wantFlipX {
+				t.Fatalf("FlipX %v, want the player turned to the attacker", player.FlipX)
+			}
+			if !player.State.IsDamaged() {
+				t.Fatal("player is not in the hurt state")
+			}
+		})
+	}
+}
+
+func TestHurtIFramesAndStun(t *testing.T) {
+	player, qt := groundedPlayer(t, Rock)
+	dt := 1.0 / TickRate
+	player.TakeHit(NewHit(10, 0))
+	health := player.Combat.Health
+
+	if res := player.TakeHit(NewHit(10, 0)); res != HitMissed {
+		t.Fatalf("second hit right away: %v, want missed", res)
+	}
+	if player.Combat.Health != health {
+		t.Fatal("a hit during the i-frames did damage")
+	}
+
+	// the stun ignores input, then the player is back on its feet
+	right := InputState{Direction: Direction{LeftRight: 1}}
+	stunTicks := HurtStunMs * TickRate / 1000
+	for i := 0; i < stunTicks-1; i++ {
+		UpdatePlayer(player, &right, qt, dt)
+		if !player.State.IsDamaged() {
+			t.Fatalf("tick %d: hurt state ended before the stun", i)
+		}
+	}
+	for i := 0; i < TickRate && player.State.IsDamaged(); i++ {
+		UpdatePlayer(player, &InputState{}, qt, dt)
+	}
+	if player.State.IsDamaged() {
+		t.Fatal("player never recovered from the hit")
+	}
+
+	// the i-frames outlast the stun
+	if !player.IsInvulnerable() {
+		t.Fatal("i-frames ended with the stun")
+	}
+	for i := 0; i < HurtIFramesMs*TickRate/1000; i++ {
+		UpdatePlayer(player, &InputState{}, qt, dt)
+	}
+	if res := player.TakeHit(NewHit(10, 0)); res != HitLanded {
+		t.Fatalf("hit after the i-frames: %v, want landed", res)
+	}
+}
+
+func TestLethalHit(t *testing.T) {
+	player, _ := groundedPlayer(t, Rock)
+	if res := player.TakeHit(NewHit(player.Combat.Health+50, 0)); res != HitLanded {
+		t.Fatalf("result %v, want landed", res)
+	}
+	if !player.State.IsDead() || player.Combat.Health != 0 {
+		t.Fatalf("dead %v with health %v, want dead at 0", player.State.IsDead(), player.Combat.Health)
+	}
+	if res := player.TakeHit(NewHit(10, 0)); res != HitMissed {
+		t.Fatalf("hit on a dead player: %v, want missed", res)
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import "math"
+
+// ------------------------ dash constants ------------------------
+const (
+	DashSpeed      = 900 // horizontal speed during the dash
+	DashDurationMs = 150
+	DashCooldownMs = 500 // counted from the start of the dash
+	DashIFramesMs  = 150 // invulnerable this long from the start of the dash
+	AirDashes      = 1
+)
+
+// ---------------- dash ----------------
+// Dash is the tuning of a character's dash plus its runtime counters
+type Dash struct {
+	Speed      float64
+	DurationMs float64
+	CooldownMs float64
+	IFramesMs  float64
+	AirDashes  int // dashes allowed before landing again, 0 for ground only
+
+	Dir            float64 // -1 left, +1 right
+	TimeLeftMs     float64 // remaining ms of the current dash
+	CooldownLeftMs float64
+	AirDashesLeft  int
+}
+
+func DefaultDash() Dash {
+	return Dash{
+		Speed:         DashSpeed,
+		DurationMs:    DashDurationMs,
+		CooldownMs:    DashCooldownMs,
+		IFramesMs:     DashIFramesMs,
+		AirDashes:     AirDashes,
+		AirDashesLeft: AirDashes,
+	}
+}
+
+// IsInvulnerable reports whether enemy attacks pass through the player, eg: mid dash
+func (player *PlayerRuntime) IsInvulnerable() bool {
+	return player.InvulnerableMs > 0
+}
+
+// tickTimers runs down the dash cooldown, the hurt stun, the invulnerability
+// window, the wall jump lock and the refused attack flash, and brings power back
+func (player *PlayerRuntime) tickTimers(dt float64) {
+	dtMs := dt * 1000
+	player.Dash.CooldownLeftMs = math.Max(0, player.Dash.CooldownLeftMs-dtMs)
+	player.HurtMs = math.Max(0, player.HurtMs-dtMs)
+	player.InvulnerableMs = math.Max(0, player.InvulnerableMs-dtMs)
+	player.Wall.LockLeftMs = math.Max(0, player.Wall.LockLeftMs-dtMs)
+	player.PowerDeniedMs = math.Max(0, player.PowerDeniedMs-dtMs)
+	player.regenPower(dt)
+	if player.OnGround {
+		player.Dash.AirDashesLeft = player.Dash.AirDashes
+	}
+}
+
+// startDash begins a dash if one was asked for and is allowed
+func (player *PlayerRuntime) startDash(inputState *InputState, canDash bool) bool {
+	d := &player.Dash
+	if !inputState.DashJustPressed || !canDash || d.CooldownLeftMs > 0 {
+		return false
+	}
+	if !player.OnGround {
+		if d.AirDashesLeft <= 0 {
+			return false
+		}
+		d.AirDashesLeft--
+	}
+
+	// dash where the stick points, or where the player faces
+	d.Dir = float64(inputState.Direction.LeftRight)
+	if d.Dir == 0 {
+		d.Dir = 1
+		if player.FlipX {
+			d.Dir = -1
+		}
+	}
+	player.FlipX = d.Dir < 0
+
+	d.TimeLeftMs = d.DurationMs
+	d.CooldownLeftMs = d.CooldownMs
+	player.InvulnerableMs = math.Max(player.InvulnerableMs, d.IFramesMs)
+	player.State.SetPlayerState(int(PlayerStateDash))
+	return true
+}
+
+// dashStep moves the player one step along the dash, gravity is off for the whole dash
+func (player *PlayerRuntime) dashStep(qt *DynamicQuadtree, dt float64) {
+	d := &player.Dash
+	player.Physics.VelX = d.Dir * d.Speed
+	player.Physics.VelY = 0
+	_, hitWall := player.Move(qt, dt)
+
+	d.TimeLeftMs -= dt * 1000
+	if d.TimeLeftMs > 0 && !hitWall {
+		return
+	}
+
+	// dash over, keep some of the momentum and let SteerX bleed the rest
+	d.TimeLeftMs = 0
+	player.Physics.VelX = d.Dir * math.Min(d.Speed, player.Physics.MaxRunSpeed)
+	if hitWall {
+		player.Physics.VelX = 0
+	}
+	if player.NearGround {
+		player.State.SetPlayerState(int(PlayerStateIdle))
+	} else {
+		player.State.SetPlayerState(int(PlayerStateFalling))
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDash(t *testing.T) {
+	player, qt := groundedPlayer(t, Rock)
+	dt := 1.0 / TickRate
+	dash := InputState{DashJustPressed: true}
+	start := player.Pos.X
+
+	UpdatePlayer(player, &dash, qt, dt)
+	if !player.State.IsDashing() {
+		t.Fatal("dash press did not start a dash")
+	}
+	if player.Physics.VelX != DashSpeed {
+		t.Fatalf("dash speed %.2f, want %v facing right", player.Physics.VelX, DashSpeed)
+	}
+	if !player.IsInvulnerable() {
+		t.Fatal("no i-frames at the start of the dash")
+	}
+
+	ticks := 1
+	for ; player.State.IsDashing() && ticks < TickRate; ticks++ {
+		UpdatePlayer(player, &InputState{}, qt, dt)
+	}
+	if want := int(math.Ceil(DashDurationMs * TickRate / 1000.0)); ticks != want {
+		t.Fatalf("dash lasted %d ticks, want %d", ticks, want)
+	}
+	if dist, want := player.Pos.X-start, DashSpeed*DashDurationMs/1000.0; math.Abs(dist-want) > DashSpeed*dt {
+		t.Fatalf("dashed %.2f, want about %.2f", dist, want)
+	}
+	if player.Physics.VelX > player.Physics.MaxRunSpeed {
+		t.Fatalf("left the dash at %.2f, faster than the run speed %v", player.Physics.VelX, player.Physics.MaxRunSpeed)
+	}
+
+	// the cooldown counts from the start of the dash
+	UpdatePlayer(player, &dash, qt, dt)
+	if player.State.IsDashing() {
+		t.Fatal("dashed again during the cooldown")
+	}
+	if player.IsInvulnerable() {
+		t.Fatal("i-frames outlasted the dash")
+	}
+	for i := 0; i < DashCooldownMs*TickRate/1000; i++ {
+		UpdatePlayer(player, &InputState{}, qt, dt)
+	}
+	UpdatePlayer(player, &dash, qt, dt)
+	if !player.State.IsDashing() {
+		t.Fatal("could not dash after the cooldown")
+	}
+}
+
+func TestDashDirection(t *testing.T) {
+	dt := 1.0 / TickRate
+	tests := []struct {
+		name    string
+		facing  bool // FlipX, facing left
+		stick   int8
+		wantDir float64
+	}{
+		{"facing right", false, 0, 1},
+		{"facing left", true, 0, -1},
+		{"stick beats facing", false, -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player, qt := groundedPlayer(t, Rock)
+			player.FlipX = tt.facing
+			UpdatePlayer(player, &InputState{DashJustPressed: true, Direction: Direction{LeftRight: tt.stick}}, qt, dt)
+			if player.Dash.Dir != tt.wantDir {
+				t.Fatalf("dash dir %v, want %v", player.Dash.Dir, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestAirDash(t *testing.T) {
+	player, err := InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.OnGround = false
+	dash := InputState{DashJustPressed: true}
+
+	for i := 0; i < AirDashes; i++ {
+		player.Dash.CooldownLeftMs = 0
+		if !player.startDash(&dash, true) {
+			t.Fatalf("air dash %d refused", i+1)
+		}
+	}
+	player.Dash.CooldownLeftMs = 0
+	if player.startDash(&dash, true) {
+		t.Fatal("dashed more times in the air than AirDashes")
+	}
+
+	// landing gives them back
+	player.OnGround = true
+	player.tickTimers(0)
+	player.OnGround = false
+	if !player.startDash(&dash, true) {
+		t.Fatal("landing did not refill the air dashes")
+	}
+}
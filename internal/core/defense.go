@@ -0,0 +1,105 @@
+package core
+
+// ------------------------ defense constants ------------------------
+const (
+	GuardDamageScale    = 0.2 // share of a frontal hit's damage that gets through the guard
+	GuardKnockbackScale = 0.3 // share of its knockback, the guard only slides back
+	ParryWindowMs       = 150 // a hit this soon after raising the guard is parried
+
+	StartingPotions = 3
+	MaxPotions      = 5
+	PotionHeal      = 40 // health one potion gives back
+)
+
+// ---------------- guard ----------------
+
+// faces reports whether world x is in front of the player
+func (player *PlayerRuntime) faces(x float64) bool {
+	center := player.Pos.X + player.Width/2
+	if player.FlipX {
+		return x <= center
+	}
+	return x >= center
+}
+
+// raiseGuard puts the guard up, the parry window opens with it
+func (player *PlayerRuntime) raiseGuard() {
+	player.GuardMs = 0
+	player.State.SetPlayerState(int(PlayerStateDefense))
+}
+
+// holdGuard keeps the guard up while defense is held
+func (player *PlayerRuntime) holdGuard(inputState *InputState, dt float64) {
+	if !inputState.Skills.Defense {
+		player.State.SetPlayerState(int(PlayerStateIdle))
+		return
+	}
+	player.GuardMs += dt * 1000
+}
+
+// guard takes a frontal hit on a raised guard. Inside the parry window
+// nothing gets through and the attacker is left to stagger, after it the
+// guard blocks most of the damage and the player slides back a little.
+func (player *PlayerRuntime) guard(hit Hit) HitResult {
+	if player.GuardMs <= ParryWindowMs {
+		return HitParried
+	}
+
+	player.Combat.TakeDamage(hit.Damage * GuardDamageScale)
+	if player.Combat.IsDead() {
+		player.Die()
+		return HitBlocked
+	}
+	dir := 1.0
+	if player.Pos.X+player.Width/2 < hit.SourceX {
+		dir = -1
+	}
+	player.Physics.VelX = dir * hit.KnockbackX * GuardKnockbackScale
+	return HitBlocked
+}
+
+// ---------------- potions ----------------
+// Potions is the player's potion inventory
+type Potions struct {
+	Count int
+	Max   int
+}
+
+// DefaultPotions is the inventory a new game starts with
+func DefaultPotions() Potions {
+	return Potions{Count: StartingPotions, Max: MaxPotions}
+}
+
+// Add puts up to n potions in the inventory, never above Max, and returns how many fit
+func (p *Potions) Add(n int) int {
+	if n > p.Max-p.Count {
+		n = p.Max - p.Count
+	}
+	if n <= 0 {
+		return 0
+	}
+	p.Count += n
+	return n
+}
+
+// Refill tops the inventory up to StartingPotions, eg: on respawn
+func (p *Potions) Refill() {
+	if p.Count < StartingPotions {
+		p.Count = StartingPotions
+	}
+}
+
+// canDrinkPotion reports whether a potion would do anything
+func (player *PlayerRuntime) canDrinkPotion() bool {
+	return player.Potions.Count > 0 && player.Combat.Health < player.Combat.MaxHealth
+}
+
+// drinkPotion uses up a potion at the end of the drink animation, getting
+// hit before that spills nothing
+func (player *PlayerRuntime) drinkPotion() {
+	if player.Potions.Count <= 0 {
+		return
+	}
+	player.Potions.Count--
+	player.Combat.Heal(PotionHeal)
+}
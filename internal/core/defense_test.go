@@ -0,0 +1,91 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGuardTiming(t *testing.T) {
+	dt := 1.0 / TickRate
+	tests := []struct {
+		name        string
+		guardTicks  int     // ticks the guard has been held when the hit comes
+		sourceX     float64 // the player faces right from x 100
+		want        HitResult
+		wantDamage  float64
+		wantKnockVX float64
+	}{
+		{"parried on raising", 0, 1000, HitParried, 0, 0},
+		{"parried at the end of the window", ParryWindowMs * TickRate / 1000, 1000, HitParried, 0, 0},
+		{"blocked after the window", ParryWindowMs*TickRate/1000 + 1, 1000, HitBlocked, 10 * GuardDamageScale, -KnockbackX * GuardKnockbackScale},
+		{"from behind gets through", 0, 0, HitLanded, 10, KnockbackX},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player, _ := groundedPlayer(t, Rock)
+			player.FlipX = false
+			health := player.Combat.Health
+
+			player.raiseGuard()
+			held := InputState{Skills: Skills{Defense: true}}
+			for i := 0; i < tt.guardTicks; i++ {
+				player.holdGuard(&held, dt)
+			}
+
+			if res := player.TakeHit(NewHit(10, tt.sourceX)); res != tt.want {
+				t.Fatalf("result %v after %.0fms of guard, want %v", res, player.GuardMs, tt.want)
+			}
+			if got := health - player.Combat.Health; math.Abs(got-tt.wantDamage) > 1e-9 {
+				t.Fatalf("took %v damage, want %v", got, tt.wantDamage)
+			}
+			if math.Abs(player.Physics.VelX-tt.wantKnockVX) > 1e-9 {
+				t.Fatalf("knocked back at %.2f, want %.2f", player.Physics.VelX, tt.wantKnockVX)
+			}
+		})
+	}
+}
+
+func TestGuardDropsOnRelease(t *testing.T) {
+	player, _ := groundedPlayer(t, Rock)
+	player.raiseGuard()
+	player.holdGuard(&InputState{}, 1.0/TickRate)
+	if player.State.IsDefense() {
+		t.Fatal("guard stayed up with defense released")
+	}
+}
+
+func TestPotions(t *testing.T) {
+	player, _ := groundedPlayer(t, Rock)
+	if player.canDrinkPotion() {
+		t.Fatal("can drink a potion at full health")
+	}
+
+	player.Combat.Health = player.Combat.MaxHealth - PotionHeal - 10
+	player.drinkPotion()
+	if player.Potions.Count != StartingPotions-1 || player.Combat.Health != player.Combat.MaxHealth-10 {
+		t.Fatalf("count %d, health %v after a potion", player.Potions.Count, player.Combat.Health)
+	}
+	player.drinkPotion()
+	if player.Combat.Health != player.Combat.MaxHealth {
+		t.Fatalf("potion healed past max health to %v", player.Combat.Health)
+	}
+
+	player.Potions.Count = 0
+	player.Combat.Health = 1
+	if player.canDrinkPotion() {
+		t.Fatal("can drink with no potions left")
+	}
+	player.drinkPotion()
+	if player.Combat.Health != 1 || player.Potions.Count != 0 {
+		t.Fatal("drinking from an empty inventory did something")
+	}
+
+	if n := player.Potions.Add(MaxPotions + 3); n != MaxPotions || player.Potions.Count != MaxPotions {
+		t.Fatalf("Add put in %d for a count of %d, want both capped at %d", n, player.Potions.Count, MaxPotions)
+	}
+	player.Potions.Count = 1
+	player.Potions.Refill()
+	if player.Potions.Count != StartingPotions {
+		t.Fatalf("Refill left %d potions, want %d", player.Potions.Count, StartingPotions)
+	}
+}
@@ -0,0 +1,289 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ------------------------ dynamic platform constants ------------------------
+// Moving and crumbling platforms are placed as level objects. Their custom
+// properties, all optional:
+//
+//	material  tile type to draw and collide as, default wood
+//	shape     eg: "oneway"
+//	path      moving only: waypoints in tiles from the start, eg: "4,0 4,-2"
+//	speed     moving only: world units per second
+//	loop      moving only: "true" runs the path as a loop instead of back and forth
+//	delay     crumbling only: seconds it holds once stood on
+//	respawn   crumbling only: seconds until it comes back
+const (
+	PlatformMaterialProperty = "material"
+	PlatformShapeProperty    = "shape"
+	PlatformPathProperty     = "path"
+	PlatformSpeedProperty    = "speed"
+	PlatformLoopProperty     = "loop"
+	PlatformDelayProperty    = "delay"
+	PlatformRespawnProperty  = "respawn"
+
+	DefaultPlatformMaterial = Wood
+	DefaultPlatformPath     = "3,0"
+	DefaultPlatformSpeed    = 120
+	DefaultCrumbleDelay     = 0.5
+	DefaultCrumbleRespawn   = 3.0
+
+	crumbleShake = 2.0 // how far a crumbling platform shakes, in world units
+)
+
+// ---------------- moving platform ----------------
+// PlatformMotion moves a platform along a path of waypoints at a fixed speed
+type PlatformMotion struct {
+	Path  []Position // top left corner at every waypoint, Path[0] is where it starts
+	Speed float64
+	Loop  bool // after the last waypoint head for the first, otherwise turn back
+
+	Target  int      // waypoint it is heading for
+	Dir     int      // +1 or -1 along the path when going back and forth
+	PrevPos Position // position at the previous step (for interpolation)
+
+	DeltaX, DeltaY float64 // how far it moved during the last step, riders move as much
+}
+
+// next picks the waypoint after Target
+func (m *PlatformMotion) next() {
+	if m.Loop {
+		m.Target = (m.Target + 1) % len(m.Path)
+		return
+	}
+	if m.Target+m.Dir < 0 || m.Target+m.Dir >= len(m.Path) {
+		m.Dir = -m.Dir
+	}
+	m.Target += m.Dir
+}
+
+// ---------------- crumbling platform ----------------
+type CrumbleState int
+
+const (
+	CrumbleIntact CrumbleState = iota
+	CrumbleShaking
+	CrumbleBroken
+)
+
+// Crumble breaks a platform a moment after something stands on it and puts it back later
+type Crumble struct {
+	DelaySec   float64
+	RespawnSec float64
+
+	State CrumbleState
+	Timer float64 // seconds in the current state
+}
+
+// Trigger starts the countdown of an intact platform
+func (c *Crumble) Trigger() {
+	if c.State == CrumbleIntact {
+		c.State = CrumbleShaking
+		c.Timer = 0
+	}
+}
+
+// Restore puts a shaking or broken crumbling platform back the way the level
+// had it, back into qt if it had left
+func (p *Platform) Restore(qt *DynamicQuadtree) {
+	c := p.Crumble
+	if c == nil || c.State == CrumbleIntact {
+		return
+	}
+	if c.State == CrumbleBroken {
+		qt.Insert(p)
+	}
+	c.State, c.Timer = CrumbleIntact, 0
+}
+
+// IsDynamic reports whether the platform has to be updated every tick
+func (p *Platform) IsDynamic() bool {
+	return p.Motion != nil || p.Crumble != nil
+}
+
+// Update moves the platform one step of dt seconds along its path, or runs
+// its crumble timer, and keeps qt up to date. Broken platforms leave qt until
+// they come back.
+func (p *Platform) Update(qt *DynamicQuadtree, dt float64) {
+	if m := p.Motion; m != nil {
+		m.PrevPos = Position{X: p.X, Y: p.Y}
+		p.followPath(dt)
+		m.DeltaX, m.DeltaY = p.X-m.PrevPos.X, p.Y-m.PrevPos.Y
+		qt.Update(p)
+	}
+
+	c := p.Crumble
+	if c == nil || c.State == CrumbleIntact {
+		return
+	}
+	c.Timer += dt
+	switch {
+	case c.State == CrumbleShaking && c.Timer >= c.DelaySec:
+		c.State, c.Timer = CrumbleBroken, 0
+		qt.Remove(p)
+	case c.State == CrumbleBroken && c.Timer >= c.RespawnSec:
+		c.State, c.Timer = CrumbleIntact, 0
+		qt.Insert(p)
+	}
+}
+
+// followPath moves the platform Speed*dt along its path, through as many
+// waypoints as that takes
+func (p *Platform) followPath(dt float64) {
+	m := p.Motion
+	dist := m.Speed * dt
+	for dist > 0 && len(m.Path) > 1 {
+		target := m.Path[m.Target]
+		dx, dy := target.X-p.X, target.Y-p.Y
+		d := math.Hypot(dx, dy)
+		if d > dist {
+			p.X += dx / d * dist
+			p.Y += dy / d * dist
+			return
+		}
+		p.X, p.Y = target.X, target.Y
+		dist -= d
+		m.next()
+	}
+}
+
+// DrawPos is where to draw the platform between the last two steps, shaking
+// if it is about to crumble
+func (p *Platform) DrawPos(alpha float64) (x, y float64) {
+	x, y = p.X, p.Y
+	if m := p.Motion; m != nil {
+		x = m.PrevPos.X + (p.X-m.PrevPos.X)*alpha
+		y = m.PrevPos.Y + (p.Y-m.PrevPos.Y)*alpha
+	}
+	if c := p.Crumble; c != nil && c.State == CrumbleShaking {
+		x += crumbleShake * math.Sin(c.Timer*60)
+	}
+	return x, y
+}
+
+// ---------------- building from level objects ----------------
+
+// IsPlatformEntity reports whether k is an object that becomes a platform
+func IsPlatformEntity(k EntityKind) bool {
+	return k == EntityMovingPlatform || k == EntityCrumblingPlatform
+}
+
+// NewDynamicPlatform builds the moving or crumbling platform an object describes.
+// It is a whole number of tiles wide, one tile high, top left on the object.
+func NewDynamicPlatform(spawn EntitySpawn) (Platform, error) {
+	prop := func(name, def string) string {
+		if v, ok := spawn.Properties[name]; ok && v != "" {
+			return v
+		}
+		return def
+	}
+
+	tileType, shape, err := ParseTileShape(prop(PlatformMaterialProperty, string(DefaultPlatformMaterial)))
+	if err != nil {
+		return Platform{}, err
+	}
+	if s := prop(PlatformShapeProperty, ""); s != "" {
+		shape = PlatformShape(s)
+	}
+	if !IsTileType(tileType) {
+		return Platform{}, fmt.Errorf("unknown material %q", tileType)
+	}
+	if !IsPlatformShape(shape) || shape.IsSlope() {
+		return Platform{}, fmt.Errorf("shape %q can't move or crumble", shape)
+	}
+
+	// the tile art, then moved onto the object
+	plat := getTileInfo(0, 0, tileType, shape, false, Platform{TileInfo: Tile{TileType: Empty}})
+	plat.X += spawn.X
+	plat.Y += spawn.Y
+	plat.Width = LevelTileWidth * math.Max(1, math.Round(spawn.Width/LevelTileWidth))
+
+	switch spawn.Kind {
+	case EntityMovingPlatform:
+		speed, err := strconv.ParseFloat(prop(PlatformSpeedProperty, strconv.Itoa(DefaultPlatformSpeed)), 64)
+		if err != nil || speed <= 0 {
+			return Platform{}, fmt.Errorf("bad %s %q", PlatformSpeedProperty, spawn.Properties[PlatformSpeedProperty])
+		}
+		loop := prop(PlatformLoopProperty, "false") == "true"
+		path, err := parsePath(prop(PlatformPathProperty, DefaultPlatformPath), Position{X: plat.X, Y: plat.Y}, loop)
+		if err != nil {
+			return Platform{}, err
+		}
+		plat.Motion = &PlatformMotion{
+			Path:    path,
+			Speed:   speed,
+			Loop:    loop,
+			Target:  1,
+			Dir:     1,
+			PrevPos: path[0],
+		}
+
+	case EntityCrumblingPlatform:
+		delay, err := strconv.ParseFloat(prop(PlatformDelayProperty, fmt.Sprint(DefaultCrumbleDelay)), 64)
+		if err != nil || delay < 0 {
+			return Platform{}, fmt.Errorf("bad %s %q", PlatformDelayProperty, spawn.Properties[PlatformDelayProperty])
+		}
+		respawn, err := strconv.ParseFloat(prop(PlatformRespawnProperty, fmt.Sprint(DefaultCrumbleRespawn)), 64)
+		if err != nil || respawn < 0 {
+			return Platform{}, fmt.Errorf("bad %s %q", PlatformRespawnProperty, spawn.Properties[PlatformRespawnProperty])
+		}
+		plat.Crumble = &Crumble{DelaySec: delay, RespawnSec: respawn}
+
+	default:
+		return Platform{}, fmt.Errorf("%q is not a platform", spawn.Kind)
+	}
+	return plat, nil
+}
+
+// parsePath reads waypoints written as "dx,dy" tile offsets from start,
+// separated by spaces or semicolons. start is always the first waypoint.
+func parsePath(s string, start Position, loop bool) ([]Position, error) {
+	path := []Position{start}
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ';' }) {
+		xs, ys, ok := strings.Cut(field, ",")
+		dx, errX := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+		dy, errY := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+		if !ok || errX != nil || errY != nil {
+			return nil, fmt.Errorf("bad waypoint %q in %s %q", field, PlatformPathProperty, s)
+		}
+		path = append(path, Position{X: start.X + dx*LevelTileWidth, Y: start.Y + dy*LevelTileHeight})
+	}
+	if len(path) < 2 {
+		return nil, fmt.Errorf("%s %q has no waypoints", PlatformPathProperty, s)
+	}
+
+	// a zero length leg would stop the platform for good
+	for i := 1; i < len(path); i++ {
+		if path[i] == path[i-1] {
+			return nil, fmt.Errorf("%s %q repeats a waypoint", PlatformPathProperty, s)
+		}
+	}
+	if loop && path[len(path)-1] == path[0] {
+		path = path[:len(path)-1] // looping back to the start is implied
+	}
+	if len(path) < 2 {
+		return nil, fmt.Errorf("%s %q goes nowhere", PlatformPathProperty, s)
+	}
+	return path, nil
+}
+
+// addDynamicPlatforms turns the moving and crumbling platform objects of a
+// level into platforms
+func (l *LevelData) addDynamicPlatforms() error {
+	for _, spawn := range l.Spawns {
+		if !IsPlatformEntity(spawn.Kind) {
+			continue
+		}
+		plat, err := NewDynamicPlatform(spawn)
+		if err != nil {
+			return fmt.Errorf("%s at (%.0f, %.0f): %w", spawn.Kind, spawn.X, spawn.Y, err)
+		}
+		l.Platforms = append(l.Platforms, plat)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// newDynamicPlatform builds the platform an object of kind at (x, y) with props describes
+func newDynamicPlatform(t *testing.T, kind EntityKind, x, y float64, props map[string]string) *Platform {
+	t.Helper()
+	p, err := NewDynamicPlatform(EntitySpawn{Kind: kind, X: x, Y: y, Width: LevelTileWidth, Height: LevelTileHeight, Properties: props})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &p
+}
+
+// runPlatform updates p for the given seconds, one tick at a time
+func runPlatform(p *Platform, qt *DynamicQuadtree, seconds float64) {
+	for i := 0; i < int(math.Round(seconds*TickRate)); i++ {
+		p.Update(qt, 1.0/TickRate)
+	}
+}
+
+func TestMovingPlatformPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]string
+		at    []Position // where it is after each second
+	}{
+		{"back and forth", map[string]string{PlatformPathProperty: "1,0", PlatformSpeedProperty: "60"},
+			[]Position{{60, 0}, {0, 0}, {60, 0}}},
+		{"loop", map[string]string{PlatformPathProperty: "1,0 1,1", PlatformSpeedProperty: "60", PlatformLoopProperty: "true"},
+			[]Position{{60, 0}, {60, 60}, {60 - 60/math.Sqrt2, 60 - 60/math.Sqrt2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt := NewDynamicQuadtree(AABB{X: -600, Y: -600, Width: 1200, Height: 1200})
+			p := newDynamicPlatform(t, EntityMovingPlatform, 0, 0, tt.props)
+			qt.Insert(p)
+			for i, want := range tt.at {
+				runPlatform(p, qt, 1)
+				if math.Abs(p.X-want.X) > 1e-6 || math.Abs(p.Y-want.Y) > 1e-6 {
+					t.Fatalf("after %ds at (%.2f, %.2f), want (%.2f, %.2f)", i+1, p.X, p.Y, want.X, want.Y)
+				}
+			}
+		})
+	}
+}
+
+func TestMovingPlatformCarriesRider(t *testing.T) {
+	qt := NewDynamicQuadtree(AABB{X: 0, Y: 0, Width: 2000, Height: 2000})
+	p := newDynamicPlatform(t, EntityMovingPlatform, 300, 600, map[string]string{PlatformPathProperty: "5,0", PlatformSpeedProperty: "120"})
+	qt.Insert(p)
+
+	b := standingBody(p.X+10, p.Y)
+	stepBody(&b, qt, 0)
+	if b.StandingOn() != p {
+		t.Fatal("body is not standing on the platform")
+	}
+
+	offset := b.Pos.X - p.X
+	for i := 0; i < TickRate; i++ {
+		p.Update(qt, 1.0/TickRate)
+		stepBody(&b, qt, 0)
+		if !b.OnGround || b.Pos.Y+b.Height != p.Y {
+			t.Fatalf("tick %d: body left the platform, feet %.2f, top %.2f", i, b.Pos.Y+b.Height, p.Y)
+		}
+	}
+	if math.Abs(b.Pos.X-p.X-offset) > 1e-6 {
+		t.Fatalf("body slid %.2f along the platform, want it carried", b.Pos.X-p.X-offset)
+	}
+}
+
+func TestCrumblingPlatform(t *testing.T) {
+	qt := NewDynamicQuadtree(AABB{X: 0, Y: 0, Width: 2000, Height: 2000})
+	p := newDynamicPlatform(t, EntityCrumblingPlatform, 300, 600, map[string]string{PlatformDelayProperty: "0.5", PlatformRespawnProperty: "2"})
+	qt.Insert(p)
+	present := func() bool {
+		for _, obj := range qt.Retrieve(p.GetBounds()) {
+			if obj == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	runPlatform(p, qt, 1)
+	if p.Crumble.State != CrumbleIntact {
+		t.Fatal("crumbled without anything standing on it")
+	}
+
+	p.Crumble.Trigger()
+	runPlatform(p, qt, 0.4)
+	if p.Crumble.State != CrumbleShaking || !present() {
+		t.Fatalf("state %v before the delay ran out, want shaking and solid", p.Crumble.State)
+	}
+	runPlatform(p, qt, 0.2)
+	if p.Crumble.State != CrumbleBroken || present() {
+		t.Fatalf("state %v after the delay, want broken and out of the quadtree", p.Crumble.State)
+	}
+	runPlatform(p, qt, 2)
+	if p.Crumble.State != CrumbleIntact || !present() {
+		t.Fatalf("state %v after the respawn time, want intact and back", p.Crumble.State)
+	}
+
+	// Restore skips the wait
+	p.Crumble.Trigger()
+	runPlatform(p, qt, 1)
+	p.Restore(qt)
+	if p.Crumble.State != CrumbleIntact || !present() {
+		t.Fatalf("state %v after Restore, want intact and back", p.Crumble.State)
+	}
+}
+
+func TestNewDynamicPlatformErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  EntityKind
+		props map[string]string
+		want  string
+	}{
+		{"unknown material", EntityMovingPlatform, map[string]string{PlatformMaterialProperty: "cheese"}, "unknown material"},
+		{"slope", EntityCrumblingPlatform, map[string]string{PlatformShapeProperty: string(ShapeSlope45R)}, "can't move or crumble"},
+		{"zero speed", EntityMovingPlatform, map[string]string{PlatformSpeedProperty: "0"}, "bad speed"},
+		{"bad waypoint", EntityMovingPlatform, map[string]string{PlatformPathProperty: "3"}, "bad waypoint"},
+		{"repeated waypoint", EntityMovingPlatform, map[string]string{PlatformPathProperty: "1,0 1,0"}, "repeats a waypoint"},
+		{"loop back to the start only", EntityMovingPlatform, map[string]string{PlatformPathProperty: "0,0", PlatformLoopProperty: "true"}, "repeats a waypoint"},
+		{"negative delay", EntityCrumblingPlatform, map[string]string{PlatformDelayProperty: "-1"}, "bad delay"},
+		{"not a platform", EntityEnemyBasic, nil, "is not a platform"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDynamicPlatform(EntitySpawn{Kind: tt.kind, Width: LevelTileWidth, Height: LevelTileHeight, Properties: tt.props})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
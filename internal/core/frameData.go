@@ -0,0 +1,251 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"player/assets"
+)
+
+// ------------------------ frame data ------------------------
+// Frame data is the combat side of the animations: per frame hitboxes that
+// hurt others, hurtboxes that can be hurt and events like footsteps. It lives
+// in assets/framedata.json, one section per character, one entry per
+// animation name, so combat tuning is a data edit.
+
+// Box is a rectangle relative to the top left of a body, laid out for the
+// body facing right
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"w"`
+	Height float64 `json:"h"`
+}
+
+// On puts the box on a body, mirrored across it when it faces left
+func (b Box) On(body AABB, flipX bool) AABB {
+	box := AABB{X: body.X + b.X, Y: body.Y + b.Y, Width: b.Width, Height: b.Height}
+	if flipX {
+		box.X = body.X + body.Width - b.X - b.Width
+	}
+	return box
+}
+
+// Overlaps reports whether any box of a touches any box of b
+func Overlaps(a, b []AABB) bool {
+	for _, boxA := range a {
+		for _, boxB := range b {
+			if boxA.Intersects(boxB) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type FrameEventKind string
+
+const (
+	EventFootstep        FrameEventKind = "footstep"
+	EventSpawnProjectile FrameEventKind = "spawnProjectile" // Name picks the projectile
+	EventPlaySound       FrameEventKind = "playSound"       // Name picks the sound
+)
+
+// FrameEvent fires once when its frame comes up
+type FrameEvent struct {
+	Kind FrameEventKind `json:"kind"`
+	Name string         `json:"name,omitempty"`
+}
+
+// Frame is what one frame of an animation carries
+type Frame struct {
+	Hitboxes  []Box        `json:"hitboxes,omitempty"`
+	Hurtboxes []Box        `json:"hurtboxes,omitempty"` // empty means the whole body
+	Events    []FrameEvent `json:"events,omitempty"`
+}
+
+// HitboxesOn puts the frame's hitboxes on a body
+func (f Frame) HitboxesOn(body AABB, flipX bool) []AABB {
+	boxes := make([]AABB, len(f.Hitboxes))
+	for i, b := range f.Hitboxes {
+		boxes[i] = b.On(body, flipX)
+	}
+	return boxes
+}
+
+// HurtboxesOn puts the frame's hurtboxes on a body, a frame without any can
+// be hurt anywhere on the body
+func (f Frame) HurtboxesOn(body AABB, flipX bool) []AABB {
+	if len(f.Hurtboxes) == 0 {
+		return []AABB{body}
+	}
+	boxes := make([]AABB, len(f.Hurtboxes))
+	for i, b := range f.Hurtboxes {
+		boxes[i] = b.On(body, flipX)
+	}
+	return boxes
+}
+
+// FrameData is the frame data of one animation. On disk frames are keyed by
+// frame, counted from the animation's first frame, or by a range, eg: "2-4".
+type FrameData struct {
+	Damage     float64    `json:"damage"` // dealt by each of its hitboxes
+	KnockbackX float64    `json:"knockbackX"`
+	KnockbackY float64    `json:"knockbackY"`
+	PowerCost  float64    `json:"powerCost"` // power spent going into the animation
+	PowerGain  float64    `json:"powerGain"` // power gained for every target it hits
+	Cancel     string     `json:"cancel"`    // frames a combo can cut it short on, eg: "5-7"
+	Status     StatusKind `json:"status"`    // status effect its hits leave, see StatusKinds
+
+	Frames map[string]Frame `json:"frames"`
+
+	perFrame    []Frame // Frames expanded, one per frame of the animation
+	cancelFirst int     // Cancel parsed, an empty range without one
+	cancelLast  int
+}
+
+// At is the data of frame, counted from the animation's first frame
+func (d *FrameData) At(frame int) Frame {
+	if d == nil || frame < 0 || frame >= len(d.perFrame) {
+		return Frame{}
+	}
+	return d.perFrame[frame]
+}
+
+// Hit is what one of the animation's hitboxes deals, coming from sourceX
+func (d *FrameData) Hit(sourceX float64) Hit {
+	return Hit{Damage: d.Damage, SourceX: sourceX, KnockbackX: d.KnockbackX, KnockbackY: d.KnockbackY, Status: d.Status}
+}
+
+// HasHitboxes reports whether any frame of the animation can hurt
+func (d *FrameData) HasHitboxes() bool {
+	if d == nil {
+		return false
+	}
+	for _, f := range d.perFrame {
+		if len(f.Hitboxes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// InCancelWindow reports whether a combo can cut the animation short on frame
+func (d *FrameData) InCancelWindow(frame int) bool {
+	return d != nil && frame >= d.cancelFirst && frame <= d.cancelLast
+}
+
+// expand is a copy of d with its frames spread over totalFrames frames
+func (d FrameData) expand(totalFrames int) (*FrameData, error) {
+	d.perFrame = make([]Frame, totalFrames)
+	if _, ok := StatusKinds[d.Status]; d.Status != "" && !ok {
+		return nil, fmt.Errorf("unknown status %q", d.Status)
+	}
+	d.cancelFirst, d.cancelLast = 0, -1
+	if d.Cancel != "" {
+		first, last, err := parseFrameRange(d.Cancel)
+		if err != nil {
+			return nil, fmt.Errorf("cancel: %w", err)
+		}
+		if first < 0 || last >= totalFrames || first > last {
+			return nil, fmt.Errorf("cancel %q out of range, the animation has %d", d.Cancel, totalFrames)
+		}
+		d.cancelFirst, d.cancelLast = first, last
+	}
+	keys := make([]string, 0, len(d.Frames))
+	for key := range d.Frames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // events sharing a frame fire in the same order every run
+	for _, key := range keys {
+		frame := d.Frames[key]
+		first, last, err := parseFrameRange(key)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 || last >= totalFrames || first > last {
+			return nil, fmt.Errorf("frames %q out of range, the animation has %d", key, totalFrames)
+		}
+		for i := first; i <= last; i++ {
+			f := &d.perFrame[i]
+			f.Hitboxes = append(f.Hitboxes, frame.Hitboxes...)
+			f.Hurtboxes = append(f.Hurtboxes, frame.Hurtboxes...)
+			f.Events = append(f.Events, frame.Events...)
+		}
+	}
+	return &d, nil
+}
+
+func parseFrameRange(key string) (first, last int, err error) {
+	from, to, isRange := strings.Cut(key, "-")
+	if first, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+		return 0, 0, fmt.Errorf("bad frame %q", key)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+		return 0, 0, fmt.Errorf("bad frame range %q", key)
+	}
+	return first, last, nil
+}
+
+// ---------------- loading ----------------
+
+// FrameDataSet is a frame data file: character -> animation name -> frame data
+type FrameDataSet map[string]map[string]*FrameData
+
+// ParseFrameData reads the contents of a frame data file
+func ParseFrameData(data []byte) (FrameDataSet, error) {
+	var set FrameDataSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("frame data: %w", err)
+	}
+	return set, nil
+}
+
+// LoadFrameData reads a frame data file from disk, eg: to try out tuning
+// without rebuilding
+func LoadFrameData(path string) (FrameDataSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set, err := ParseFrameData(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// DefaultFrameData is the frame data the game ships with, embedded from
+// assets/framedata.json
+func DefaultFrameData() (FrameDataSet, error) {
+	return ParseFrameData(assets.FrameData)
+}
+
+// AttachFrameData checks the frame data of one character against its
+// animations and hands each animation its data. names maps animation names
+// to animation keys, frameCount gives the number of frames behind a key.
+func AttachFrameData(character string, data map[string]*FrameData, names map[string]int, frameCount func(key int) (int, bool), attach func(key int, d *FrameData)) error {
+	for name, d := range data {
+		key, ok := names[name]
+		if !ok {
+			return fmt.Errorf("frame data %s: unknown animation %q", character, name)
+		}
+		total, ok := frameCount(key)
+		if !ok {
+			return fmt.Errorf("frame data %s: animation %q has no frames", character, name)
+		}
+		expanded, err := d.expand(total)
+		if err != nil {
+			return fmt.Errorf("frame data %s %s: %w", character, name, err)
+		}
+		attach(key, expanded)
+	}
+	return nil
+}
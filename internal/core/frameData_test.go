@@ -0,0 +1,208 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFrameRange(t *testing.T) {
+	tests := []struct {
+		key         string
+		first, last int
+		wantErr     bool
+	}{
+		{"3", 3, 3, false},
+		{"2-4", 2, 4, false},
+		{" 5 - 7 ", 5, 7, false},
+		{"", 0, 0, true},
+		{"x", 0, 0, true},
+		{"2-", 0, 0, true},
+		{"-1", 0, 0, true},
+	}
+	for _, tt := range tests {
+		first, last, err := parseFrameRange(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseFrameRange(%q) err = %v, want an error %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if first != tt.first || last != tt.last {
+			t.Errorf("parseFrameRange(%q) = %d, %d, want %d, %d", tt.key, first, last, tt.first, tt.last)
+		}
+	}
+}
+
+func TestExpandCancelWindow(t *testing.T) {
+	tests := []struct {
+		cancel string
+		frames []int // frames of the 8 in the window
+	}{
+		{"", nil},
+		{"6-7", []int{6, 7}},
+		{"3", []int{3}},
+		{"0-7", []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		d, err := FrameData{Cancel: tt.cancel}.expand(8)
+		if err != nil {
+			t.Fatalf("cancel %q: %v", tt.cancel, err)
+		}
+		in := map[int]bool{}
+		for _, f := range tt.frames {
+			in[f] = true
+		}
+		for f := -1; f <= 8; f++ {
+			if got := d.InCancelWindow(f); got != in[f] {
+				t.Errorf("cancel %q: InCancelWindow(%d) = %v, want %v", tt.cancel, f, got, in[f])
+			}
+		}
+	}
+
+	var none *FrameData
+	if none.InCancelWindow(0) {
+		t.Error("an animation with no frame data has a cancel window")
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data FrameData
+		want string
+	}{
+		{"cancel past the end", FrameData{Cancel: "7-8"}, "out of range"},
+		{"cancel backwards", FrameData{Cancel: "5-3"}, "out of range"},
+		{"cancel not a number", FrameData{Cancel: "late"}, "cancel"},
+		{"frames past the end", FrameData{Frames: map[string]Frame{"6-9": {}}}, "out of range"},
+		{"frames backwards", FrameData{Frames: map[string]Frame{"4-2": {}}}, "out of range"},
+		{"frames not a number", FrameData{Frames: map[string]Frame{"a": {}}}, "bad frame"},
+		{"unknown status", FrameData{Status: "sleepy"}, "unknown status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.data.expand(8)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoxOn(t *testing.T) {
+	body := AABB{X: 100, Y: 50, Width: 40, Height: 60}
+	box := Box{X: 30, Y: 20, Width: 50, Height: 10}
+	tests := []struct {
+		flipX bool
+		want  AABB
+	}{
+		{false, AABB{X: 130, Y: 70, Width: 50, Height: 10}},
+		{true, AABB{X: 60, Y: 70, Width: 50, Height: 10}}, // mirrored across the body
+	}
+	for _, tt := range tests {
+		if got := box.On(body, tt.flipX); got != tt.want {
+			t.Errorf("flipX %v: %+v, want %+v", tt.flipX, got, tt.want)
+		}
+	}
+
+	if got := (Frame{}).HurtboxesOn(body, false); len(got) != 1 || got[0] != body {
+		t.Errorf("a frame without hurtboxes is hurt on %+v, want the whole body", got)
+	}
+}
+
+func TestExpandFrames(t *testing.T) {
+	hit := Box{Width: 10, Height: 10}
+	step := FrameEvent{Kind: EventFootstep}
+	d, err := FrameData{Frames: map[string]Frame{
+		"2":   {Events: []FrameEvent{step}},
+		"2-3": {Hitboxes: []Box{hit}},
+	}}.expand(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		frame    int
+		hitboxes int
+		events   int
+	}{
+		{-1, 0, 0},
+		{0, 0, 0},
+		{2, 1, 1}, // a single frame and a range sharing it both land
+		{3, 1, 0},
+		{4, 0, 0},
+		{5, 0, 0},
+	}
+	for _, tt := range tests {
+		f := d.At(tt.frame)
+		if len(f.Hitboxes) != tt.hitboxes || len(f.Events) != tt.events {
+			t.Errorf("frame %d: %d hitboxes %d events, want %d and %d", tt.frame, len(f.Hitboxes), len(f.Events), tt.hitboxes, tt.events)
+		}
+	}
+	if !d.HasHitboxes() {
+		t.Error("HasHitboxes is false with hitboxes on frames 2-3")
+	}
+}
+
+func TestAttachFrameDataErrors(t *testing.T) {
+	names := map[string]int{"swing": 1, "empty": 2}
+	frameCount := func(key int) (int, bool) { return 4, key == 1 }
+	attach := func(int, *FrameData) {}
+	tests := []struct {
+		name string
+		data map[string]*FrameData
+		want string
+	}{
+		{"unknown animation", map[string]*FrameData{"kick": {}}, `unknown animation "kick"`},
+		{"no frames", map[string]*FrameData{"empty": {}}, "has no frames"},
+		{"bad range", map[string]*FrameData{"swing": {Cancel: "2-9"}}, "swing: cancel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AttachFrameData("hero", tt.data, names, frameCount, attach)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestWeakAttackFrames plays the weak attack through and checks its hitbox is
+// only out on the frames the shipped frame data gives it, and its sound fires once
+func TestWeakAttackFrames(t *testing.T) {
+	player, qt := groundedPlayer(t, Rock)
+	dt := 1.0 / TickRate
+	start := player.Animations[WeakAttack].AnimStartFrame
+
+	hitFrames := map[int]bool{}
+	sounds := 0
+	input := InputState{Skills: Skills{WeakAttack: true}}
+	for i := 0; i < TickRate; i++ {
+		UpdatePlayer(player, &input, qt, dt)
+		player.UpdateAnimation(dt)
+		input = InputState{}
+		if !player.State.IsWeakAttack() {
+			break
+		}
+
+		frame := player.CurrAnimFrame - start
+		for _, box := range player.AttackHitboxes() {
+			hitFrames[frame] = true
+			if want := player.Pos.X + 30; box.X != want {
+				t.Fatalf("frame %d: hitbox at x %v, want %v", frame, box.X, want)
+			}
+		}
+		for _, e := range player.FrameEvents {
+			if e.Kind == EventPlaySound && e.Name == "swing" {
+				sounds++
+			}
+		}
+	}
+
+	for frame := 0; frame < 8; frame++ {
+		if want := frame >= 3 && frame <= 5; hitFrames[frame] != want {
+			t.Errorf("frame %d: hitbox out %v, want %v", frame, hitFrames[frame], want)
+		}
+	}
+	if sounds != 1 {
+		t.Errorf("swing sound fired %d times, want once", sounds)
+	}
+}
@@ -1 +1,26 @@
 package core
+
+// ---------------- game state ----------------
+type GameState int
+
+const (
+	ModeMenu GameState = iota
+	ModePlaying
+	ModePaused
+	ModeGameOver
+)
+
+func (m GameState) String() string {
+	switch m {
+	case ModeMenu:
+		return "menu"
+	case ModePlaying:
+		return "playing"
+	case ModePaused:
+		return "paused"
+	case ModeGameOver:
+		return "game over"
+	default:
+		return "unknown"
+	}
+}
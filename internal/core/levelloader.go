@@ -0,0 +1,59 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// LevelLoader turns a map file into platforms and entity spawns
+type LevelLoader interface {
+	Load(path string) (*LevelData, error)
+}
+
+// PNGLoader reads one-pixel-per-tile level images, resolved with the palette next to them
+type PNGLoader struct{}
+
+func (PNGLoader) Load(path string) (*LevelData, error) {
+	levelData, err := LoadLevelImage(path)
+	if err != nil {
+		return nil, err
+	}
+	palette, err := LoadPaletteFor(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadLevel(levelData, palette)
+}
+
+// LoaderFor picks the loader for a map file from its extension. Tiled maps
+// saved as plain .json are not picked up, save them as .tmj.
+func LoaderFor(path string) (LevelLoader, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return PNGLoader{}, nil
+	case ".tmx", ".tmj":
+		return TiledLoader{}, nil
+	default:
+		return nil, fmt.Errorf("no level loader for %s", path)
+	}
+}
+
+// LoadLevelFile loads any supported map file
+func LoadLevelFile(path string) (*LevelData, error) {
+	loader, err := LoaderFor(path)
+	if err != nil {
+		return nil, err
+	}
+	level, err := loader.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := level.addDynamicPlatforms(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := level.checkProjectiles(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return level, nil
+}
@@ -0,0 +1,112 @@
+package core
+
+// ------------------------ swimming constants ------------------------
+const (
+	SwimGravityScale = 0.15 // share of gravity left in water
+	SwimSinkSpeed    = 100  // fall speed cap in water
+	SwimStrokeSpeed  = 250  // rise speed while holding jump or up
+	SwimExitScale    = 0.7  // share of JumpForce used to hop out at the surface
+)
+
+// ---------------- material ----------------
+// Material is how a tile type behaves under whatever stands on or in it
+type Material struct {
+	Friction        float64    // multiplier on DecX, eg: ice < 1 slides
+	Accel           float64    // multiplier on AccX
+	SpeedScale      float64    // multiplier on the target speed
+	DamagePerSecond float64    // health lost per second of contact
+	Status          StatusKind // effect contact leaves, eg: lava sets bodies on fire
+
+	Solid     bool // blocks movement, non-solid tiles are only drawn
+	Swimmable bool // bodies inside switch to swimming
+	Hazard    bool // hurts on contact
+}
+
+// DefaultMaterial is plain solid ground, also used in the air
+var DefaultMaterial = Material{Friction: 1, Accel: 1, SpeedScale: 1, Solid: true}
+
+// Materials is the material table, tile types missing from it use DefaultMaterial
+var Materials = map[TileType]Material{
+	Grass: DefaultMaterial,
+	Rock:  DefaultMaterial,
+	Metal: DefaultMaterial,
+	Wood:  DefaultMaterial,
+	Ice:   {Friction: 0.1, Accel: 0.3, SpeedScale: 1, Solid: true},
+	Sand:  {Friction: 2, Accel: 0.5, SpeedScale: 0.6, Solid: true},
+	Water: {Friction: 1, Accel: 0.5, SpeedScale: 0.5, Swimmable: true},
+	Larva: {Friction: 1, Accel: 1, SpeedScale: 1, DamagePerSecond: 40, Status: StatusBurn, Solid: true, Hazard: true},
+}
+
+// MaterialOf looks up the material of a tile type
+func MaterialOf(t TileType) Material {
+	if m, ok := Materials[t]; ok {
+		return m
+	}
+	return DefaultMaterial
+}
+
+// Material of the platform's tile
+func (p *Platform) Material() Material {
+	return MaterialOf(p.TileInfo.TileType)
+}
+
+// IsSolid reports whether bodies collide with the platform
+func (p *Platform) IsSolid() bool {
+	return p.Material().Solid
+}
+
+// solidPlatform returns obj as a platform if it is one that blocks movement
+func solidPlatform(obj Collider) (*Platform, bool) {
+	p, ok := obj.(*Platform)
+	if !ok || !p.IsSolid() {
+		return nil, false
+	}
+	return p, true
+}
+
+// swim replaces gravity and jumping while the player is in water: a slow
+// sink, and holding jump or up strokes towards the surface
+func (player *PlayerRuntime) swim(inputState *InputState, dt float64) {
+	dtUnits := 100.0 * dt
+	phys := &player.Physics
+
+	phys.VelY += phys.GravityScale * dtUnits * SwimGravityScale
+	if inputState.JumpHeld || inputState.Direction.UpDown > 0 {
+		phys.VelY = Approach(phys.VelY, -SwimStrokeSpeed, phys.AccY*dtUnits)
+	}
+	if phys.VelY > SwimSinkSpeed {
+		phys.VelY = SwimSinkSpeed
+	}
+
+	// water is as good as ground for refilling jumps
+	phys.CoyoteMs = 0
+	phys.AirJumpsLeft = phys.MaxAirJumps
+	phys.JumpBufferLeft = 0
+}
+
+// leaveWater runs on the first step out of the water, stroking up at the
+// surface hops the player out
+func (player *PlayerRuntime) leaveWater(inputState *InputState) {
+	if inputState.JumpHeld || inputState.Direction.UpDown > 0 {
+		player.Physics.VelY = -player.Physics.JumpForce * SwimExitScale
+		player.State.SetPlayerState(int(PlayerStateJumping))
+		return
+	}
+	player.State.SetPlayerState(int(PlayerStateFalling))
+}
+
+// applyHazards hurts the player for standing on or being in a hazard tile,
+// and leaves the tile's status effect on
+func (player *PlayerRuntime) applyHazards(dt float64) {
+	if player.OnGround {
+		if m := MaterialOf(player.Ground); m.Hazard {
+			player.Combat.TakeDamage(m.DamagePerSecond * dt)
+			player.Statuses.Apply(m.Status)
+			return
+		}
+	}
+	if m := MaterialOf(player.Medium); m.Hazard {
+		player.Combat.TakeDamage(m.DamagePerSecond * dt)
+		player.Statuses.Apply(m.Status)
+	}
+}
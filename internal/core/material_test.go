@@ -0,0 +1,74 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// groundedPlayer is a fresh player standing on a long floor of tile type
+// ground, with a ledge of the same tile far off to the right
+func groundedPlayer(t *testing.T, ground TileType) (*PlayerRuntime, *DynamicQuadtree) {
+	t.Helper()
+	player, err := InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	qt := NewDynamicQuadtree(AABB{X: 0, Y: 0, Width: 20000, Height: 2000})
+	qt.Insert(&Platform{X: 0, Y: 1000, Width: 20000, Height: LevelTileHeight, TileInfo: Tile{TileType: ground}})
+
+	player.Reset(Position{X: 100, Y: 1000 - PlayerHeight})
+	settle(&player, qt)
+	if !player.OnGround {
+		t.Fatal("player did not land on the floor")
+	}
+	return &player, qt
+}
+
+// settle steps the player with no input until it stands still
+func settle(player *PlayerRuntime, qt *DynamicQuadtree) {
+	for i := 0; i < TickRate; i++ {
+		UpdatePlayer(player, &InputState{}, qt, 1.0/TickRate)
+	}
+}
+
+// TestMaterialFriction runs the player to top speed on each ground, lets go
+// and measures how far it slides
+func TestMaterialFriction(t *testing.T) {
+	slide := map[TileType]float64{}
+	for _, ground := range []TileType{Rock, Ice, Sand} {
+		player, qt := groundedPlayer(t, ground)
+		right := InputState{Direction: Direction{LeftRight: 1}}
+		for i := 0; i < 3*TickRate; i++ {
+			UpdatePlayer(player, &right, qt, 1.0/TickRate)
+		}
+		want := player.Physics.MaxSpeed * MaterialOf(ground).SpeedScale
+		if math.Abs(player.Physics.VelX-want) > 1e-9 {
+			t.Fatalf("%s: top speed %.2f, want %.2f", ground, player.Physics.VelX, want)
+		}
+
+		start := player.Pos.X
+		for i := 0; i < 10*TickRate && player.Physics.VelX != 0; i++ {
+			UpdatePlayer(player, &InputState{}, qt, 1.0/TickRate)
+		}
+		if player.Physics.VelX != 0 {
+			t.Fatalf("%s: still sliding at %.2f after 10s", ground, player.Physics.VelX)
+		}
+		slide[ground] = player.Pos.X - start
+	}
+
+	if !(slide[Ice] > slide[Rock] && slide[Rock] > slide[Sand]) {
+		t.Fatalf("slides ice %.2f, rock %.2f, sand %.2f: want ice the longest and sand the shortest", slide[Ice], slide[Rock], slide[Sand])
+	}
+}
+
+func TestMaterialOf(t *testing.T) {
+	if m := MaterialOf(Empty); m != DefaultMaterial {
+		t.Fatalf("MaterialOf(Empty) = %+v, want the default", m)
+	}
+	if !MaterialOf(Water).Swimmable || MaterialOf(Water).Solid {
+		t.Fatal("water is not a swimmable non-solid tile")
+	}
+	if m := MaterialOf(Larva); !m.Hazard || m.DamagePerSecond <= 0 || m.Status != StatusBurn {
+		t.Fatalf("lava = %+v, want a burning hazard", m)
+	}
+}
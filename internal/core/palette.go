@@ -0,0 +1,184 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PaletteExt is appended to the level name to find its palette,
+// eg: Level_1.png -> Level_1.palette.json
+const PaletteExt = ".palette.json"
+
+// Palette maps the colours of a level image to tiles and entities.
+// Fully transparent pixels are always empty.
+type Palette struct {
+	Empty    map[color.RGBA]bool
+	Tiles    map[color.RGBA]TileType
+	Shapes   map[color.RGBA]PlatformShape // tile colours that aren't full blocks
+	Entities map[color.RGBA]EntityKind
+}
+
+// paletteFile is the on-disk form of a palette, colours are written as "#RRGGBB".
+// Tiles may carry a shape after the tile type, eg: "wood/oneway", "stone/slope45r".
+type paletteFile struct {
+	Empty    []string              `json:"empty"`
+	Tiles    map[string]TileType   `json:"tiles"`
+	Entities map[string]EntityKind `json:"entities"`
+}
+
+// DefaultPalette is the palette levels used before palette files existed
+func DefaultPalette() *Palette {
+	return &Palette{
+		Empty: map[color.RGBA]bool{
+			{255, 255, 255, 255}: true,
+		},
+		Tiles: map[color.RGBA]TileType{
+			{255, 0, 0, 255}:   Larva,
+			{0, 255, 0, 255}:   Grass,
+			{0, 0, 255, 255}:   Water,
+			{255, 255, 0, 255}: Rock,
+		},
+		Entities: map[color.RGBA]EntityKind{
+			{0, 0, 0, 255}: EntityEnemyBasic,
+		},
+	}
+}
+
+// PalettePath returns where the palette of the level at levelPath lives
+func PalettePath(levelPath string) string {
+	return strings.TrimSuffix(levelPath, filepath.Ext(levelPath)) + PaletteExt
+}
+
+// LoadPaletteFor loads the palette sitting next to the level at levelPath,
+// falling back to DefaultPalette when the level has none
+func LoadPaletteFor(levelPath string) (*Palette, error) {
+	palette, err := LoadPalette(PalettePath(levelPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return DefaultPalette(), nil
+	}
+	return palette, err
+}
+
+// LoadPalette reads and validates a palette file
+func LoadPalette(path string) (*Palette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file paletteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("palette %s: %w", path, err)
+	}
+
+	palette := &Palette{
+		Empty:    make(map[color.RGBA]bool),
+		Tiles:    make(map[color.RGBA]TileType),
+		Shapes:   make(map[color.RGBA]PlatformShape),
+		Entities: make(map[color.RGBA]EntityKind),
+	}
+	seen := make(map[color.RGBA]string)
+	claim := func(hex, use string) (color.RGBA, error) {
+		c, err := parseHexColor(hex)
+		if err != nil {
+			return c, fmt.Errorf("palette %s: %s: %w", path, use, err)
+		}
+		if prev, ok := seen[c]; ok {
+			return c, fmt.Errorf("palette %s: colour %s used for both %s and %s", path, hex, prev, use)
+		}
+		seen[c] = use
+		return c, nil
+	}
+
+	for _, hex := range file.Empty {
+		c, err := claim(hex, "empty")
+		if err != nil {
+			return nil, err
+		}
+		palette.Empty[c] = true
+	}
+	for hex, value := range file.Tiles {
+		tileType, shape, err := ParseTileShape(string(value))
+		if err != nil {
+			return nil, fmt.Errorf("palette %s: colour %s: %w", path, hex, err)
+		}
+		if !IsTileType(tileType) {
+			return nil, fmt.Errorf("palette %s: colour %s: unknown tile type %q", path, hex, tileType)
+		}
+		c, err := claim(hex, string(value))
+		if err != nil {
+			return nil, err
+		}
+		palette.Tiles[c] = tileType
+		if shape != ShapeSolid {
+			palette.Shapes[c] = shape
+		}
+	}
+	for hex, kind := range file.Entities {
+		if !IsEntityKind(kind) {
+			return nil, fmt.Errorf("palette %s: colour %s: unknown entity %q", path, hex, kind)
+		}
+		c, err := claim(hex, string(kind))
+		if err != nil {
+			return nil, err
+		}
+		palette.Entities[c] = kind
+	}
+	return palette, nil
+}
+
+// Lookup resolves a pixel to a tile or an entity. Empty pixels return Empty and
+// no entity, colours the palette does not know about are an error.
+func (p *Palette) Lookup(c color.Color) (TileType, EntityKind, error) {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	if rgba.A == 0 {
+		return Empty, "", nil
+	}
+	// the palette only describes opaque colours
+	rgba.A = 255
+
+	if p.Empty[rgba] {
+		return Empty, "", nil
+	}
+	if tileType, ok := p.Tiles[rgba]; ok {
+		return tileType, "", nil
+	}
+	if kind, ok := p.Entities[rgba]; ok {
+		return Empty, kind, nil
+	}
+	return Empty, "", fmt.Errorf("unknown colour %s", hexColor(rgba))
+}
+
+// ShapeAt is the shape of the tile a pixel resolves to, ShapeSolid for full
+// blocks and anything that isn't a tile
+func (p *Palette) ShapeAt(c color.Color) PlatformShape {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	if rgba.A == 0 {
+		return ShapeSolid
+	}
+	rgba.A = 255
+	return p.Shapes[rgba]
+}
+
+func parseHexColor(hex string) (color.RGBA, error) {
+	s := strings.TrimPrefix(hex, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, fmt.Errorf("colour %q is not #RRGGBB", hex)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("colour %q is not #RRGGBB", hex)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePalette writes a palette file to a temp dir and returns its path
+func writePalette(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "level"+PaletteExt)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPalette(t *testing.T) {
+	palette, err := LoadPalette(writePalette(t, `{
+		"empty": ["#FFFFFF"],
+		"tiles": {"#00FF00": "grass", "#8B4513": "wood/oneway"},
+		"entities": {"#0000ff": "spawn"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pixel  color.Color
+		tile   TileType
+		entity EntityKind
+		shape  PlatformShape
+	}{
+		{color.RGBA{255, 255, 255, 255}, Empty, "", ShapeSolid},
+		{color.RGBA{0, 0, 0, 0}, Empty, "", ShapeSolid},
+		{color.RGBA{0, 255, 0, 255}, Grass, "", ShapeSolid},
+		{color.RGBA{0x8B, 0x45, 0x13, 255}, Wood, "", ShapeOneWay},
+		{color.RGBA{0, 0, 255, 255}, Empty, EntitySpawnPoint, ShapeSolid},
+	}
+	for _, tt := range tests {
+		tile, entity, err := palette.Lookup(tt.pixel)
+		if err != nil {
+			t.Fatalf("Lookup(%v): %v", tt.pixel, err)
+		}
+		if tile != tt.tile || entity != tt.entity {
+			t.Errorf("Lookup(%v) = %q, %q, want %q, %q", tt.pixel, tile, entity, tt.tile, tt.entity)
+		}
+		if shape := palette.ShapeAt(tt.pixel); shape != tt.shape {
+			t.Errorf("ShapeAt(%v) = %v, want %v", tt.pixel, shape, tt.shape)
+		}
+	}
+}
+
+func TestLoadPaletteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"duplicate colour", `{"tiles": {"#00FF00": "grass"}, "entities": {"#00ff00": "spawn"}}`, "used for both"},
+		{"duplicate empty", `{"empty": ["#FFFFFF", "#ffffff"]}`, "used for both"},
+		{"short hex", `{"tiles": {"#0F0": "grass"}}`, "not #RRGGBB"},
+		{"bad hex digit", `{"empty": ["#GGGGGG"]}`, "not #RRGGBB"},
+		{"unknown tile", `{"tiles": {"#00FF00": "lava"}}`, "unknown tile type"},
+		{"unknown shape", `{"tiles": {"#00FF00": "grass/round"}}`, "unknown shape"},
+		{"unknown entity", `{"entities": {"#00FF00": "dragon"}}`, "unknown entity"},
+		{"not json", `tiles: grass`, "palette"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPalette(writePalette(t, tt.data))
+			if err == nil {
+				t.Fatal("loaded a bad palette")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPaletteMissing(t *testing.T) {
+	levelPath := filepath.Join(t.TempDir(), "level.png")
+	if _, err := LoadPalette(PalettePath(levelPath)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("LoadPalette of a missing file: err = %v, want fs.ErrNotExist", err)
+	}
+
+	// a level with no palette next to it uses the default one
+	palette, err := LoadPaletteFor(levelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tile, _, _ := palette.Lookup(color.RGBA{0, 255, 0, 255}); tile != Grass {
+		t.Fatalf("fallback palette reads green as %q, want grass", tile)
+	}
+}
+
+func TestLoadLevelUnknownColour(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.Set(x, y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+	img.Set(1, 1, color.RGBA{12, 34, 56, 255})
+
+	_, err := LoadLevel(img, DefaultPalette())
+	if err == nil {
+		t.Fatal("loaded a level with a colour the palette does not know")
+	}
+	if !strings.Contains(err.Error(), "(1, 1)") || !strings.Contains(err.Error(), "#0C2238") {
+		t.Fatalf("err = %v, want the pixel and its colour", err)
+	}
+}
@@ -1,5 +1,7 @@
 package core
 
+import "math"
+
 // AABB represents an Axis-Aligned Bounding Box
 type AABB struct {
 	X, Y, Width, Height float64
@@ -13,8 +15,130 @@ func (a AABB) Intersects(b AABB) bool {
 		a.Y+a.Height > b.Y
 }
 
+// Contains checks if the point x, y lies inside the box
+func (a AABB) Contains(x, y float64) bool {
+	return x >= a.X && x < a.X+a.Width && y >= a.Y && y < a.Y+a.Height
+}
+
+// Union is the smallest box around both a and b
+func (a AABB) Union(b AABB) AABB {
+	x, y := math.Min(a.X, b.X), math.Min(a.Y, b.Y)
+	return AABB{
+		X:      x,
+		Y:      y,
+		Width:  math.Max(a.X+a.Width, b.X+b.Width) - x,
+		Height: math.Max(a.Y+a.Height, b.Y+b.Height) - y,
+	}
+}
+
 // Collider is an interface for any object that has a bounding box
 type Collider interface {
 	// here GetBounds returns the bounding box of the collider
 	GetBounds() AABB
 }
+
+// ---------------- swept collision ----------------
+
+// sweepSkin is how far two boxes may overlap and still count as touching,
+// it absorbs the float error left after snapping a body onto a surface
+const sweepSkin = 1e-6
+
+// Contact is where a moving box first touches a collider
+type Contact struct {
+	Time             float64 // fraction of the move done before touching, 0..1
+	NormalX, NormalY float64 // surface normal of the face that was hit, eg: (0, -1) for a floor
+	Collider         Collider
+}
+
+// axisSweep returns when, as a fraction of d, the span [a, a+aLen) starts and
+// stops overlapping [b, b+bLen) while moving by d
+func axisSweep(a, aLen, d, b, bLen float64) (entry, exit float64) {
+	if d == 0 {
+		if a < b+bLen && a+aLen > b {
+			return math.Inf(-1), math.Inf(1) // overlapping the whole move
+		}
+		return math.Inf(1), math.Inf(-1) // never overlapping
+	}
+
+	var near, far float64
+	if d > 0 {
+		near, far = b-(a+aLen), b+bLen-a
+	} else {
+		near, far = b+bLen-a, b-(a+aLen)
+	}
+	if near*d < 0 && math.Abs(near) <= sweepSkin {
+		near = 0 // resting on the surface, not inside it
+	}
+	return near / d, far / d
+}
+
+// SweepAABB moves a by (dx, dy) and reports the first time it touches b.
+// Boxes that already overlap at the start of the move do not count, so a
+// body that ends up inside something can always move out of it.
+func SweepAABB(a AABB, dx, dy float64, b AABB) (Contact, bool) {
+	entryX, exitX := axisSweep(a.X, a.Width, dx, b.X, b.Width)
+	entryY, exitY := axisSweep(a.Y, a.Height, dy, b.Y, b.Height)
+
+	entry := math.Max(entryX, entryY)
+	exit := math.Min(exitX, exitY)
+	if entry >= exit || entry < 0 || entry > 1 {
+		return Contact{}, false
+	}
+
+	c := Contact{Time: entry}
+	if entryX > entryY {
+		c.NormalX = -math.Copysign(1, dx)
+	} else {
+		c.NormalY = -math.Copysign(1, dy)
+	}
+	return c, true
+}
+
+// SweepPlatforms moves a by (dx, dy) through the solid platforms in qt and
+// returns the earliest contact. One-way platforms only stop a box falling onto
+// them from above, unless passOneWay is set. Slopes only stop sideways moves
+// into their tall edge and upward moves into their underside, landing on them
+// is left to the body.
+func SweepPlatforms(qt *DynamicQuadtree, a AABB, dx, dy float64, passOneWay bool) (Contact, bool) {
+	if qt == nil {
+		return Contact{}, false
+	}
+
+	// broad phase: everything the box passes over during the move
+	area := AABB{
+		X:      math.Min(a.X, a.X+dx),
+		Y:      math.Min(a.Y, a.Y+dy),
+		Width:  a.Width + math.Abs(dx),
+		Height: a.Height + math.Abs(dy),
+	}
+
+	best, hit := Contact{}, false
+	for _, obj := range qt.Retrieve(area) {
+		p, ok := solidPlatform(obj)
+		if !ok {
+			continue
+		}
+		bounds := p.GetBounds()
+		switch {
+		case p.IsOneWay():
+			// only from above, and only if the box started above the top
+			if passOneWay || dy <= 0 || a.Y+a.Height > bounds.Y+sweepSkin {
+				continue
+			}
+		case p.IsSlope():
+			if dy > 0 {
+				continue
+			}
+			if dx != 0 {
+				bounds = p.slopeFace(dx)
+			}
+		}
+		c, ok := SweepAABB(a, dx, dy, bounds)
+		if !ok || (hit && c.Time >= best.Time) {
+			continue
+		}
+		c.Collider = obj
+		best, hit = c, true
+	}
+	return best, hit
+}
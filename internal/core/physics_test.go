@@ -0,0 +1,235 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSweepAABB(t *testing.T) {
+	tile := AABB{X: 100, Y: 100, Width: 60, Height: 60}
+	tests := []struct {
+		name           string
+		a              AABB
+		dx, dy         float64
+		wantHit        bool
+		wantTime       float64
+		wantNX, wantNY float64
+	}{
+		{"falling onto the top", AABB{X: 110, Y: 0, Width: 20, Height: 40}, 0, 120, true, 0.5, 0, -1},
+		{"jumping into the bottom", AABB{X: 110, Y: 200, Width: 20, Height: 40}, 0, -80, true, 0.5, 0, 1},
+		{"running into the left side", AABB{X: 60, Y: 110, Width: 20, Height: 20}, 40, 0, true, 0.5, -1, 0},
+		{"running into the right side", AABB{X: 180, Y: 110, Width: 20, Height: 20}, -40, 0, true, 0.5, 1, 0},
+		{"diagonal hits the side first", AABB{X: 60, Y: 80, Width: 20, Height: 40}, 40, 20, true, 0.5, -1, 0},
+		{"diagonal hits the top first", AABB{X: 90, Y: 40, Width: 20, Height: 40}, 20, 40, true, 0.5, 0, -1},
+		{"resting on the top", AABB{X: 110, Y: 60, Width: 20, Height: 40}, 0, 10, true, 0, 0, -1},
+		{"falls short", AABB{X: 110, Y: 0, Width: 20, Height: 40}, 0, 50, false, 0, 0, 0},
+		{"moving away", AABB{X: 110, Y: 0, Width: 20, Height: 40}, 0, -50, false, 0, 0, 0},
+		{"passes beside", AABB{X: 200, Y: 0, Width: 20, Height: 40}, 0, 300, false, 0, 0, 0},
+		{"already inside", AABB{X: 110, Y: 110, Width: 20, Height: 20}, 0, 10, false, 0, 0, 0},
+		{"whole tile in one move", AABB{X: 110, Y: 0, Width: 20, Height: 40}, 0, 1000, true, 0.06, 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, hit := SweepAABB(tt.a, tt.dx, tt.dy, tile)
+			if hit != tt.wantHit {
+				t.Fatalf("hit = %v, want %v", hit, tt.wantHit)
+			}
+			if !hit {
+				return
+			}
+			if math.Abs(c.Time-tt.wantTime) > 1e-9 {
+				t.Errorf("time = %v, want %v", c.Time, tt.wantTime)
+			}
+			if c.NormalX != tt.wantNX || c.NormalY != tt.wantNY {
+				t.Errorf("normal = (%v, %v), want (%v, %v)", c.NormalX, c.NormalY, tt.wantNX, tt.wantNY)
+			}
+		})
+	}
+}
+
+// TestKinematicBodyDoesNotTunnel drops a body at full fall speed with a step
+// long enough to carry it past a single tile in one move
+func TestKinematicBodyDoesNotTunnel(t *testing.T) {
+	tile := Platform{X: 0, Y: 300, Width: LevelTileWidth, Height: LevelTileHeight, TileInfo: Tile{TileType: Rock}}
+	qt := NewDynamicQuadtree(AABB{X: 0, Y: 0, Width: 600, Height: 2000})
+	qt.Insert(&tile)
+
+	for _, dt := range []float64{1.0 / TickRate, 0.1, 0.5, 1} {
+		body := NewKinematicBody(Position{X: 10, Y: 0}, 40, 80, 0, Physics{VelY: 700, MaxFallSpeed: 700})
+		for i := 0; i < 100 && !body.OnGround; i++ {
+			body.Move(qt, dt)
+		}
+		if !body.OnGround {
+			t.Fatalf("dt %v: body at y %.2f never landed", dt, body.Pos.Y)
+		}
+		if feet := body.Pos.Y + body.Height; feet != tile.Y {
+			t.Fatalf("dt %v: feet at %.2f, want the top of the tile at %.2f", dt, feet, tile.Y)
+		}
+	}
+}
+
+// levelTree puts the platforms in a quadtree big enough for every test level
+func levelTree(platforms []Platform) *DynamicQuadtree {
+	qt := NewDynamicQuadtree(AABB{X: 0, Y: 0, Width: 1200, Height: 1200})
+	for i := range platforms {
+		qt.Insert(&platforms[i])
+	}
+	return qt
+}
+
+// standingBody is a player sized body with its feet at feet
+func standingBody(x, feet float64) KinematicBody {
+	b := NewKinematicBody(Position{X: x, Y: feet - PlayerHeight}, PlayerWidth, PlayerHeight, PlayerSensorDepth, Physics{
+		GravityScale: GravityScale,
+		MaxFallSpeed: MaxFallSpeed,
+	})
+	b.OnGround = true
+	return b
+}
+
+// stepBody runs one tick of a body walking at vx, with gravity
+func stepBody(b *KinematicBody, qt *DynamicQuadtree, vx float64) {
+	dt := 1.0 / TickRate
+	b.Physics.VelX = vx
+	b.ApplyGravity(dt)
+	b.Move(qt, dt)
+}
+
+// TestSlopeWalk walks up a 45° slope onto a ledge and back down, the body
+// must stay on the ground with its feet on the surface the whole way
+//
+//	          ####
+//	        / ####
+//	######  / ####
+func TestSlopeWalk(t *testing.T) {
+	const floor = 600.0
+	tile := float64(LevelTileWidth)
+	platforms := []Platform{
+		{X: 0, Y: floor, Width: tile, Height: tile, TileInfo: Tile{TileType: Rock}},
+		{X: tile, Y: floor, Width: tile, Height: tile, TileInfo: Tile{TileType: Rock}},
+		{X: 2 * tile, Y: floor - tile, Width: tile, Height: tile, TileInfo: Tile{TileType: Rock}, Shape: ShapeSlope45R},
+		{X: 3 * tile, Y: floor - tile, Width: tile, Height: tile, TileInfo: Tile{TileType: Rock}},
+		{X: 4 * tile, Y: floor - tile, Width: tile, Height: tile, TileInfo: Tile{TileType: Rock}},
+	}
+	qt := levelTree(platforms)
+
+	// surface is where the feet belong: on the slope under the middle of the
+	// feet, or on a flat top under any part of them, whichever is higher
+	surface := func(b *KinematicBody) float64 {
+		mid := b.Pos.X + b.Width/2
+		top := math.Inf(1)
+		for i := range platforms {
+			p := &platforms[i]
+			switch {
+			case p.IsSlope() && mid >= p.X && mid < p.X+p.Width:
+				top = math.Min(top, p.FloorY(mid))
+			case !p.IsSlope() && b.Pos.X < p.X+p.Width && b.Pos.X+b.Width > p.X:
+				top = math.Min(top, p.Y)
+			}
+		}
+		return top
+	}
+
+	b := standingBody(10, floor)
+	walk := func(vx float64, done func() bool) {
+		t.Helper()
+		for i := 0; i < 10*TickRate && !done(); i++ {
+			stepBody(&b, qt, vx)
+			if !b.OnGround {
+				t.Fatalf("went airborne at x %.2f, y %.2f", b.Pos.X, b.Pos.Y)
+			}
+			if feet := b.Pos.Y + b.Height; math.Abs(feet-surface(&b)) > 1 {
+				t.Fatalf("feet at %.2f at x %.2f, the surface is at %.2f", feet, b.Pos.X, surface(&b))
+			}
+		}
+		if !done() {
+			t.Fatalf("never got there, stuck at x %.2f", b.Pos.X)
+		}
+	}
+
+	walk(MaxSpeed, func() bool { return b.Pos.X > 4*tile })
+	if feet := b.Pos.Y + b.Height; feet != floor-tile {
+		t.Fatalf("feet at %.2f on the ledge, want %.2f", feet, floor-tile)
+	}
+	walk(-MaxSpeed, func() bool { return b.Pos.X < 10 })
+	if feet := b.Pos.Y + b.Height; feet != floor {
+		t.Fatalf("feet at %.2f back at the bottom, want %.2f", feet, floor)
+	}
+}
+
+// oneWayLevel is a floor with a one-way ledge 200 above it
+func oneWayLevel() (*DynamicQuadtree, *Platform) {
+	platforms := []Platform{
+		{X: 0, Y: 600, Width: 600, Height: LevelTileHeight, TileInfo: Tile{TileType: Rock}},
+		{X: 0, Y: 400, Width: 600, Height: LevelTileHeight, TileInfo: Tile{TileType: Wood}, Shape: ShapeOneWay},
+	}
+	return levelTree(platforms), &platforms[1]
+}
+
+func TestOneWayLandFromAbove(t *testing.T) {
+	qt, ledge := oneWayLevel()
+	b := standingBody(100, 300)
+	b.OnGround = false
+
+	for i := 0; i < 2*TickRate && !b.OnGround; i++ {
+		stepBody(&b, qt, 0)
+	}
+	if !b.OnOneWay() {
+		t.Fatalf("did not land on the one-way ledge, feet at %.2f", b.Pos.Y+b.Height)
+	}
+	if feet := b.Pos.Y + b.Height; feet != ledge.Y {
+		t.Fatalf("feet at %.2f, want the top of the ledge at %.2f", feet, ledge.Y)
+	}
+}
+
+func TestOneWayPassFromBelow(t *testing.T) {
+	qt, ledge := oneWayLevel()
+	b := standingBody(100, 600)
+	b.Physics.VelY = -JumpForce // peaks about 245 up, the ledge is 200
+
+	cleared := false
+	for i := 0; i < 2*TickRate; i++ {
+		stepBody(&b, qt, 0)
+		if b.Physics.VelY < 0 && b.Pos.Y+b.Height < ledge.Y {
+			cleared = true
+		}
+		if b.OnGround {
+			break
+		}
+	}
+	if !cleared {
+		t.Fatal("the ledge stopped the jump from below")
+	}
+	if feet := b.Pos.Y + b.Height; !b.OnGround || feet != ledge.Y {
+		t.Fatalf("feet at %.2f on ground %v, want to come down on the ledge at %.2f", feet, b.OnGround, ledge.Y)
+	}
+}
+
+func TestDropThrough(t *testing.T) {
+	qt, ledge := oneWayLevel()
+	player, err := InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.KinematicBody = standingBody(100, ledge.Y)
+	stepBody(&player.KinematicBody, qt, 0)
+	if !player.OnOneWay() {
+		t.Fatal("not standing on the ledge to begin with")
+	}
+
+	// jump alone doesn't drop, down+jump does
+	if player.dropThrough(&InputState{JumpJustPressed: true}, true) {
+		t.Fatal("jump without down dropped through")
+	}
+	input := InputState{JumpJustPressed: true, Direction: Direction{UpDown: -1}}
+	if !player.dropThrough(&input, true) {
+		t.Fatal("down+jump did not drop through")
+	}
+
+	for i := 0; i < 2*TickRate && !(player.OnGround && player.Pos.Y+player.Height > ledge.Y); i++ {
+		stepBody(&player.KinematicBody, qt, 0)
+	}
+	if feet := player.Pos.Y + player.Height; feet != 600 {
+		t.Fatalf("feet at %.2f, want to fall through to the floor at 600", feet)
+	}
+}
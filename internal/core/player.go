@@ -1,10 +1,6 @@
 package core
 
-import (
-	"math"
-
-	"github.com/hajimehoshi/ebiten/v2"
-)
+import "math"
 
 // ------------------------ physics constants ------------------------
 const (
@@ -18,24 +14,36 @@ const (
 	JumpForce    = 700
 	GravityScale = 10
 	TerminalVelY = 10
-	CoyoteMs     = 1
-	AirJumpsLeft = 1
+	CoyoteMs     = 100 // ms
+	JumpBufferMs = 120 // ms
+	JumpCutScale = 0.5
+	AirJumps     = 1
+
+	PlayerWidth       = 30
+	PlayerHeight      = 80
+	PlayerSensorDepth = 50 // ground sensor reach below the feet
 )
 
-func InitPlayer(img *ebiten.Image) PlayerRuntime {
+// InitPlayer builds a player at the default spawn point. It fails if the
+// animations' frame data or the combos don't check out.
+func InitPlayer() (PlayerRuntime, error) {
+	animations, err := InitPlayerAnimations()
+	if err != nil {
+		return PlayerRuntime{}, err
+	}
+	combos, err := DefaultCombos()
+	if err != nil {
+		return PlayerRuntime{}, err
+	}
 	return PlayerRuntime{
-		img:           img,
 		State:         PlayerState{CurrentState: PlayerStateIdle},
 		PreviousState: PlayerState{CurrentState: PlayerStateIdle},
-		Animations:    InitPlayerAnimations(),
+		Animations:    animations,
+		Combos:        combos,
 		FlipX:         false,
 		Scale:         1.0,
 		Camera:        Camera{Zoom: 1.0},
-		Pos: Position{
-			X: 100,
-			Y: 100,
-		},
-		Physics: Physics{
+		KinematicBody: NewKinematicBody(Position{X: DefaultSpawnX, Y: DefaultSpawnY}, PlayerWidth, PlayerHeight, PlayerSensorDepth, Physics{
 			VelX:         0,
 			VelY:         0,
 			AccX:         AccX,
@@ -48,15 +56,109 @@ func InitPlayer(img *ebiten.Image) PlayerRuntime {
 			GravityScale: GravityScale,
 			TerminalVelY: TerminalVelY,
 			CoyoteMs:     CoyoteMs,
-			AirJumpsLeft: AirJumpsLeft,
-		},
+			AirJumpsLeft: AirJumps,
+			CoyoteTimeMs: CoyoteMs,
+			JumpBufferMs: JumpBufferMs,
+			JumpCutScale: JumpCutScale,
+			MaxAirJumps:  AirJumps,
+		}),
 		Combat: Combat{
 			Health:    100,
 			MaxHealth: 100,
 			Power:     100,
 			MaxPower:  100,
+
+			PowerRegen: PlayerPowerRegen,
 		},
-		CheckpointID: "default",
+		Potions:      DefaultPotions(),
+		Dash:         DefaultDash(),
+		Wall:         DefaultWallMove(),
+		CheckpointID: DefaultCheckpointID,
+	}, nil
+}
+
+// Reset puts the player back at pos with no momentum, eg: when a level loads
+// or on respawn. Combat stats and the checkpoint carry over.
+func (player *PlayerRuntime) Reset(pos Position) {
+	player.State = PlayerState{CurrentState: PlayerStateIdle}
+	player.PreviousState = PlayerState{CurrentState: PlayerStateIdle}
+	player.CurrAnimFrame = 0
+	player.FlipX = false
+	player.Pos = pos
+	player.PrevPos = pos
+	player.Physics.VelX = 0
+	player.Physics.VelY = 0
+	player.Physics.CoyoteMs = player.Physics.CoyoteTimeMs
+	player.Physics.AirJumpsLeft = player.Physics.MaxAirJumps
+	player.Physics.JumpBufferLeft = 0
+	player.Dash.TimeLeftMs = 0
+	player.Dash.CooldownLeftMs = 0
+	player.Dash.AirDashesLeft = player.Dash.AirDashes
+	player.HurtMs = 0
+	player.InvulnerableMs = 0
+	player.GuardMs = 0
+	player.PowerDeniedMs = 0
+	player.Combo.Break()
+	player.Statuses.Clear()
+	player.Wall.LockLeftMs = 0
+	player.DropThroughMs = 0
+	player.GroundPlatform = nil
+	player.Camera = Camera{Zoom: 1.0}
+}
+
+// Die stops the player where it is, puts out what burns it and plays the
+// dead animation, the player takes no more input until it is Reset
+func (player *PlayerRuntime) Die() {
+	player.State.SetPlayerState(int(PlayerStateDead))
+	player.Statuses.Clear()
+	player.Physics.VelX = 0
+	player.Physics.VelY = 0
+}
+
+// updateJump handles coyote time, the jump buffer, air jumps and short hops.
+// OnGround is still the result of the previous step here.
+func (player *PlayerRuntime) updateJump(inputState *InputState, canJump bool, dt float64) {
+	phys := &player.Physics
+	dtMs := dt * 1000
+
+	// standing on something refills coyote time and air jumps, in the air they run out
+	if player.OnGround {
+		phys.CoyoteMs = phys.CoyoteTimeMs
+		phys.AirJumpsLeft = phys.MaxAirJumps
+	} else {
+		phys.CoyoteMs = math.Max(0, phys.CoyoteMs-dtMs)
+	}
+
+	// remember the press for a moment, so pressing just before landing still jumps
+	if inputState.JumpJustPressed {
+		phys.JumpBufferLeft = phys.JumpBufferMs
+	} else {
+		phys.JumpBufferLeft = math.Max(0, phys.JumpBufferLeft-dtMs)
+	}
+
+	if canJump && phys.JumpBufferLeft > 0 {
+		jumped := false
+		if player.OnGround || phys.CoyoteMs > 0 {
+			jumped = true
+		} else if inputState.JumpJustPressed && phys.AirJumpsLeft > 0 {
+			// air jumps need a fresh press, a buffered one is saved for the landing
+			phys.AirJumpsLeft--
+			jumped = true
+		}
+		if jumped {
+			player.State.SetPlayerState(int(PlayerStateJumping))
+			phys.VelY = -phys.JumpForce // Instant impulse
+			phys.CoyoteMs = 0
+			phys.JumpBufferLeft = 0
+		}
+	}
+
+	// letting go early cuts the rise short
+	if !inputState.JumpHeld && player.State.IsJumping() {
+		minVelY := -phys.JumpForce * phys.JumpCutScale
+		if phys.VelY < minVelY {
+			phys.VelY = minVelY
+		}
 	}
 }
 
@@ -100,51 +202,55 @@ func ReduceRight(current, target, maxDelta float64) float64 {
 	return current
 }
 
-// GetBounds returns the bounding box of the player
-func (player *PlayerRuntime) GetBounds() AABB {
-	return AABB{
-		X:      player.Pos.X,
-		Y:      player.Pos.Y,
-		Width:  30,
-		Height: 80,
-	}
-}
-
-func (player *PlayerRuntime) GetGroundSensor() AABB {
-	sensorHeight := 50.0
-	return AABB{
-		X:      player.Pos.X,
-		Y:      player.Pos.Y + 80,
-		Width:  30,
-		Height: sensorHeight,
-	}
-}
-
-func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuadtree) {
+// UpdatePlayer advances the player by one simulation step of dt seconds
+func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuadtree, dt float64) {
 	// Update previous state at the start of the frame
 	player.PreviousState = PlayerState{CurrentState: PlayerStateType(player.State.GetPlayerState())}
+	player.PrevPos = player.Pos
 
-	// Time Management
-	tps := float64(ebiten.TPS())
-	if tps <= 0 {
-		tps = 60
+	// the dead don't move, World decides when to respawn
+	if player.State.IsDead() {
+		return
 	}
-	dt := 1.0 / tps
-	dtUnits := 100.0 / tps // Scaling factor for physics constants
+
+	// Time Management
+	dtUnits := 100.0 * dt // Scaling factor for physics constants
 
 	// Check Permissions
 	// Define which states allow movement input.
 	// We allow movement in air, idle, run, etc., but block it during "Action" states.
 	canMove := player.State.IsIdle() || player.State.IsMoving() ||
 		player.State.IsRunning() || player.State.IsJumping() ||
-		player.State.IsFalling() || player.State.IsWeakAttackInAir() || player.State.IsStrongAttackInAir()
+		player.State.IsFalling() || player.State.IsWeakAttackInAir() || player.State.IsStrongAttackInAir() ||
+		player.State.IsWallSliding() || player.State.IsWallJumping() || player.State.IsSwimming()
+
+	// Dash: owns the whole step while it lasts
+	player.tickTimers(dt)
+	player.updateStatuses(dt)
+	if player.Statuses.Stunned() {
+		inputState = &InputState{} // a stunned player takes no input
+	}
+	if player.State.IsDashing() || player.startDash(inputState, canMove || player.State.IsLanding()) {
+		player.dashStep(qt, dt)
+		qt.Update(player)
+		return
+	}
 
 	// Input Processing
+	// right after a wall jump the kick off the wall can't be steered away
+	wallLocked := player.Wall.LockLeftMs > 0
 	inputX := 0.0
-	if canMove {
+	if canMove && !wallLocked {
 		inputX = float64(inputState.Direction.LeftRight)
 	}
 
+	// the ground under the feet, or the water around the body, changes how the player moves
+	mat := player.GroundMaterial()
+	swimming := player.InWater()
+	if swimming {
+		mat = MaterialOf(player.Medium)
+	}
+
 	targetVX := inputX * player.Physics.MaxSpeed
 	if inputState.RunJustPressed {
 		// Run logic: slightly slower air control if not grounded
@@ -155,9 +261,11 @@ func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuad
 		}
 	}
 
+	targetVX *= mat.SpeedScale * player.Statuses.SpeedScale()
+
 	// X Physics (Acceleration & Friction)
-	accX := player.Physics.AccX * dtUnits
-	decX := player.Physics.DecX * dtUnits
+	accX := player.Physics.AccX * dtUnits * mat.Accel
+	decX := player.Physics.DecX * dtUnits * mat.Friction
 
 	step := accX
 	// Apply friction if no input and on the ground
@@ -173,85 +281,44 @@ func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuad
 	}
 
 	// Apply Velocity Changes
-	if math.Abs(player.Physics.VelX) > player.Physics.MaxSpeed && !inputState.RunJustPressed {
-		// Decelerate from run speed to normal max speed
-		step = decX
-		if player.Physics.VelX > 0 {
-			player.Physics.VelX = ReduceRight(player.Physics.VelX, player.Physics.MaxSpeed, step)
-		} else if player.Physics.VelX < 0 {
-			player.Physics.VelX = ReduceLeft(player.Physics.VelX, -player.Physics.MaxSpeed, step)
-		}
-	} else {
-		player.Physics.VelX = Approach(player.Physics.VelX, targetVX, step)
+	if !wallLocked {
+		player.SteerX(targetVX, step, decX, inputState.RunJustPressed)
 	}
 
 	// Y Physics (Gravity & Jumping)
-	player.Physics.VelY += player.Physics.GravityScale * dtUnits
+	if swimming {
+		player.swim(inputState, dt)
+	} else {
+		player.ApplyGravity(dt)
+		player.capWallSlide()
 
-	// Jump Input
-	if inputState.JumpJustPressed && player.State.IsGrounded() && canMove {
-		player.State.SetPlayerState(int(PlayerStateJumping))
-		player.Physics.VelY = -player.Physics.JumpForce // Instant impulse
+		// Jump Input: down+jump drops through a one-way platform, otherwise
+		// jump off a wall first, then off the ground or in the air
+		if !player.dropThrough(inputState, canMove) && !player.wallJump(inputState, canMove) {
+			player.updateJump(inputState, canMove || player.State.IsLanding(), dt)
+		}
 	}
 
 	// Integration & Collision Resolution
-	// Apply X
-	player.Pos.X += player.Physics.VelX * dt
-	if qt != nil {
-		objs := qt.Retrieve(player.GetBounds())
-		for _, obj := range objs {
-			if _, ok := obj.(*Platform); ok {
-				bounds := obj.GetBounds()
-				if player.GetBounds().Intersects(bounds) {
-					if player.Physics.VelX > 0 { // Moving Right
-						player.Pos.X = bounds.X - player.GetBounds().Width
-					} else if player.Physics.VelX < 0 { // Moving Left
-						player.Pos.X = bounds.X + bounds.Width
-					}
-					player.Physics.VelX = 0
-				}
-			}
+	player.Move(qt, dt)
+	player.applyHazards(dt)
+	player.recoverFromHurt()
+
+	// Water overrides the rest of the state machine
+	if player.InWater() {
+		if canMove {
+			player.State.SetPlayerState(int(PlayerStateSwim))
 		}
+		qt.Update(player)
+		return
 	}
-
-	// Apply Y
-	if player.Physics.VelY < player.Physics.MaxFallSpeed {
-		player.Pos.Y += player.Physics.VelY * dt
-	} else {
-		player.Physics.VelY = player.Physics.MaxFallSpeed
-		player.Pos.Y += player.Physics.VelY * dt
+	if player.State.IsSwimming() {
+		player.leaveWater(inputState)
 	}
 
-	onGround := false
-	detectGround := false
-
-	if qt != nil {
-		objs := qt.Retrieve(player.GetBounds())
-		sensorObjs := player.GetGroundSensor()
-		for _, obj := range objs {
-			if _, ok := obj.(*Platform); ok {
-				bounds := obj.GetBounds()
-
-				// Ground detection (sensor only)
-				if sensorObjs.Intersects(bounds) {
-					detectGround = true
-				}
-
-				// Physics collision (body only)
-				if player.GetBounds().Intersects(bounds) {
-					if player.Physics.VelY > 0 { // Falling/Landing
-						player.Pos.Y = bounds.Y - player.GetBounds().Height
-						onGround = true
-						player.Physics.VelY = 0
-					} else if player.Physics.VelY < 0 { // Bonking head
-						player.Pos.Y = bounds.Y + bounds.Height
-						// fmt.Println("Bonking head and velY is", player.Physics.VelY)
-						player.Physics.VelY = 0
-					}
-				}
-			}
-		}
-	}
+	player.updateWallSlide(inputState, canMove)
+	onGround := player.OnGround
+	detectGround := player.NearGround
 
 	// State Management
 	// Transition states based on the physical results of this frame
@@ -262,24 +329,12 @@ func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuad
 				if inputState.SmugFace {
 					// fmt.Println("Smug Face Input")
 					player.State.SetPlayerState(int(PlayerStateSmugFace))
-				} else if inputState.Skills.SpecialAttack1 {
-					// fmt.Println("Special Attack 1 Input")
-					player.State.SetPlayerState(int(PlayerStateSpecialAttack1))
-				} else if inputState.Skills.SpecialAttack2 {
-					// fmt.Println("Special Attack 2 Input")
-					player.State.SetPlayerState(int(PlayerStateSpecialAttack2))
-				} else if inputState.Skills.SpecialAttack3 {
-					// fmt.Println("Special Attack 3 Input")
-					player.State.SetPlayerState(int(PlayerStateSpecialAttack3))
-				} else if inputState.Skills.SpecialAttack4 {
-					// fmt.Println("Special Attack 4 Input")
-					player.State.SetPlayerState(int(PlayerStateSpecialAttack4))
-				} else if inputState.Skills.WeakAttack {
-					// fmt.Println("Weak Attack Input")
-					player.State.SetPlayerState(int(PlayerStateWeakAttack))
-				} else if inputState.Skills.StrongAttack {
-					// fmt.Println("Strong Attack Input")
-					player.State.SetPlayerState(int(PlayerStateStrongAttack))
+				} else if inputState.Skills.Defense {
+					player.raiseGuard()
+				} else if inputState.Skills.UsePotion && player.canDrinkPotion() {
+					player.State.SetPlayerState(int(PlayerStateUsePotion))
+				} else if player.startGroundAttack(inputState.Skills) {
+					// attacking, specials cost power
 				} else if player.Physics.VelX == 0 {
 					player.State.SetPlayerState(int(PlayerStateIdle))
 				} else {
@@ -289,6 +344,8 @@ func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuad
 						player.State.SetPlayerState(int(PlayerStateMoving))
 					}
 				}
+			} else if player.State.IsDefense() {
+				player.holdGuard(inputState, dt)
 			} else if player.State.IsFalling() && player.Physics.VelY >= 0 {
 				player.State.SetPlayerState(int(PlayerStateIdle))
 			}
@@ -298,20 +355,21 @@ func UpdatePlayer(player *PlayerRuntime, inputState *InputState, qt *DynamicQuad
 	} else {
 		// In Air
 		if player.State.IsFalling() {
-			if inputState.Skills.WeakAttack {
-				player.State.SetPlayerState(int(PlayerStateWeakAttackInAir))
-			} else if inputState.Skills.StrongAttack {
-				player.State.SetPlayerState(int(PlayerStateStrongAttackInAir))
-			}
-		} else if player.Physics.VelY > 0 && !player.State.IsFalling() && !player.State.IsLanding() && !player.State.IsWeakAttackInAir() && !player.State.IsStrongAttackInAir() {
+			player.startAirAttack(inputState.Skills)
+		} else if player.Physics.VelY > 0 && !player.State.IsFalling() && !player.State.IsLanding() && !player.State.IsWeakAttackInAir() && !player.State.IsStrongAttackInAir() && !player.State.IsWallSliding() && !player.State.IsDamaged() {
 			player.State.SetPlayerState(int(PlayerStateFalling))
 		}
 	}
+	player.updateCombo(inputState.Skills, dt)
+	player.trackSwing()
+
 	// Update spatial partition
 	qt.Update(player)
 }
 
 func (player *PlayerRuntime) UpdateCamera(screenWidth, screenHeight, levelWidth, levelHeight float64) {
+	player.Camera.PrevPos = player.Camera.Pos
+
 	minX := player.Pos.X - 2*screenWidth/3
 	maxX := player.Pos.X - screenWidth/3
 	if player.Camera.Pos.X < minX {
@@ -342,3 +400,10 @@ func (player *PlayerRuntime) UpdateCamera(screenWidth, screenHeight, levelWidth,
 		player.Camera.Pos.Y = levelHeight - screenHeight
 	}
 }
+
+// ViewCamera returns the camera interpolated between the last two simulation steps
+func (player *PlayerRuntime) ViewCamera(alpha float64) Camera {
+	cam := player.Camera
+	cam.Pos = Lerp(player.Camera.PrevPos, player.Camera.Pos, alpha)
+	return cam
+}
@@ -21,7 +21,6 @@ const (
 	PlayerStateWeakAttackInAir
 	PlayerStateStrongAttackInAir
 
-	// not used yet
 	PlayerStateDamaged
 	PlayerStateDead
 	// ---------------- menu ----------------
@@ -29,6 +28,11 @@ const (
 	// ---------------- skills ----------------
 	PlayerStateDefense
 	PlayerStateUsePotion
+	// ---------------- movement ----------------
+	PlayerStateDash
+	PlayerStateWallSlide
+	PlayerStateWallJump
+	PlayerStateSwim
 )
 
 type PlayerState struct {
@@ -116,6 +120,22 @@ func (ps *PlayerState) IsUsingPotion() bool {
 	return ps.CurrentState == PlayerStateUsePotion
 }
 
+func (ps *PlayerState) IsDashing() bool {
+	return ps.CurrentState == PlayerStateDash
+}
+
+func (ps *PlayerState) IsWallSliding() bool {
+	return ps.CurrentState == PlayerStateWallSlide
+}
+
+func (ps *PlayerState) IsWallJumping() bool {
+	return ps.CurrentState == PlayerStateWallJump
+}
+
+func (ps *PlayerState) IsSwimming() bool {
+	return ps.CurrentState == PlayerStateSwim
+}
+
 func (ps *PlayerState) GetPlayerState() int {
 	return int(ps.CurrentState)
 }
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// airPlayer is a fresh player with no air jumps, so every jump in the test
+// comes from the ground, coyote time or the buffer
+func airPlayer(t *testing.T) *PlayerRuntime {
+	t.Helper()
+	player, err := InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.Physics.MaxAirJumps = 0
+	player.Physics.AirJumpsLeft = 0
+	return &player
+}
+
+func TestCoyoteTime(t *testing.T) {
+	dt := 1.0 / TickRate
+	tests := []struct {
+		name     string
+		airTicks int // ticks since walking off the ledge when jump is pressed
+		wantJump bool
+	}{
+		{"right at the edge", 1, true},
+		{"inside the window", int(CoyoteMs*TickRate/1000) - 1, true},
+		{"after the window", int(CoyoteMs*TickRate/1000) + 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := airPlayer(t)
+			player.OnGround = true
+			player.updateJump(&InputState{}, true, dt)
+
+			player.OnGround = false // walked off the ledge
+			for i := 1; i < tt.airTicks; i++ {
+				player.updateJump(&InputState{}, true, dt)
+			}
+			player.updateJump(&InputState{JumpJustPressed: true, JumpHeld: true}, true, dt)
+
+			if jumped := player.Physics.VelY == -JumpForce; jumped != tt.wantJump {
+				t.Fatalf("jumped = %v %d ticks off the ledge, want %v", jumped, tt.airTicks, tt.wantJump)
+			}
+		})
+	}
+}
+
+func TestJumpBuffer(t *testing.T) {
+	dt := 1.0 / TickRate
+	tests := []struct {
+		name      string
+		earlyTick int // ticks before landing jump was pressed
+		wantJump  bool
+	}{
+		{"pressed on the landing tick", 0, true},
+		{"pressed just before landing", int(JumpBufferMs*TickRate/1000) - 1, true},
+		{"pressed too early", int(JumpBufferMs*TickRate/1000) + 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := airPlayer(t)
+			player.Physics.CoyoteMs = 0
+			player.OnGround = false
+
+			press := &InputState{JumpJustPressed: true, JumpHeld: true}
+			for i := tt.earlyTick; i > 0; i-- {
+				player.updateJump(press, true, dt)
+				press = &InputState{JumpHeld: true}
+				if player.Physics.VelY != 0 {
+					t.Fatal("jumped in the air with no coyote time or air jumps")
+				}
+			}
+
+			player.OnGround = true // landed
+			player.updateJump(press, true, dt)
+			if jumped := player.Physics.VelY == -JumpForce; jumped != tt.wantJump {
+				t.Fatalf("jumped = %v on landing %d ticks after the press, want %v", jumped, tt.earlyTick, tt.wantJump)
+			}
+		})
+	}
+}
+
+func TestJumpCut(t *testing.T) {
+	dt := 1.0 / TickRate
+	for _, held := range []bool{true, false} {
+		player := airPlayer(t)
+		player.OnGround = true
+		player.updateJump(&InputState{JumpJustPressed: true, JumpHeld: true}, true, dt)
+		if !player.State.IsJumping() || player.Physics.VelY != -JumpForce {
+			t.Fatalf("no jump off the ground, velY %.2f", player.Physics.VelY)
+		}
+
+		player.OnGround = false
+		player.updateJump(&InputState{JumpHeld: held}, true, dt)
+
+		want := -JumpForce * JumpCutScale // let go early, a short hop
+		if held {
+			want = -JumpForce
+		}
+		if math.Abs(player.Physics.VelY-want) > 1e-9 {
+			t.Fatalf("held %v: velY %.2f, want %.2f", held, player.Physics.VelY, want)
+		}
+	}
+}
+
+// TestShortHopIsLower jumps twice on the same floor, once holding jump and
+// once letting go right away, and compares the peaks
+func TestShortHopIsLower(t *testing.T) {
+	peak := func(holdTicks int) float64 {
+		player, qt := groundedPlayer(t, Rock)
+		top := player.Pos.Y
+		for i := 0; i < 2*TickRate; i++ {
+			input := InputState{JumpJustPressed: i == 0, JumpHeld: i < holdTicks}
+			UpdatePlayer(player, &input, qt, 1.0/TickRate)
+			top = math.Min(top, player.Pos.Y)
+		}
+		return player.Pos.Y - top
+	}
+	full, short := peak(2*TickRate), peak(1)
+	if !(short > 0 && short < full) {
+		t.Fatalf("short hop peaks %.2f up, full jump %.2f: want a lower short hop", short, full)
+	}
+}
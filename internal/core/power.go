@@ -0,0 +1,60 @@
+package core
+
+import "math"
+
+// ------------------------ power constants ------------------------
+// what each attack costs and gives back on a hit is its powerCost and
+// powerGain in assets/framedata.json
+const (
+	PlayerPowerRegen = 5   // power per second that comes back to the player on its own
+	PowerDeniedMs    = 300 // how long the player flashes when an attack is refused
+)
+
+// SpendPower takes amount of power if there is enough of it, and reports whether it did
+func (c *Combat) SpendPower(amount float64) bool {
+	if amount > c.Power {
+		return false
+	}
+	if amount > 0 {
+		c.Power -= amount
+	}
+	return true
+}
+
+// GainPower raises power by amount, never above MaxPower, and returns what was gained
+func (c *Combat) GainPower(amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	amount = math.Min(amount, c.MaxPower-c.Power)
+	c.Power += amount
+	return amount
+}
+
+// PowerCost is what going into the attack of state costs
+func (player *PlayerRuntime) PowerCost(state PlayerStateType) float64 {
+	anim, ok := player.Animations[int(state)]
+	if !ok || anim.Frames == nil {
+		return 0
+	}
+	return anim.Frames.PowerCost
+}
+
+// startAttack pays for the attack of state and goes into it. Without enough
+// power the attack is refused and the player flashes instead.
+func (player *PlayerRuntime) startAttack(state PlayerStateType) bool {
+	if !player.Combat.SpendPower(player.PowerCost(state)) {
+		player.PowerDeniedMs = PowerDeniedMs
+		return false
+	}
+	player.State.SetPlayerState(int(state))
+	return true
+}
+
+// regenPower trickles power back over time, not for the dead
+func (player *PlayerRuntime) regenPower(dt float64) {
+	if player.State.IsDead() {
+		return
+	}
+	player.Combat.GainPower(player.Combat.PowerRegen * dt)
+}
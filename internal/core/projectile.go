@@ -0,0 +1,348 @@
+package core
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// ------------------------ projectile constants ------------------------
+const (
+	MaxProjectiles = 512 // live at once, spawns past it are dropped
+
+	muzzleHeight = 0.4 // share of the shooter's height, from the top, projectiles leave at
+)
+
+// Team decides who a projectile can hurt, it passes through its own side
+type Team int
+
+const (
+	TeamPlayer Team = iota // hurts enemies
+	TeamEnemy              // hurts the player
+)
+
+// ---------------- projectile kinds ----------------
+// ProjectileKind is how one kind of projectile flies and what it does on a hit
+type ProjectileKind struct {
+	Width, Height float64
+	Speed         float64 // launch speed along the shooter's facing
+	Lift          float64 // launch speed upwards, lobbed ones arc
+	Gravity       float64 // share of the gravity bodies fall with, 0 flies straight
+	LifetimeSec   float64 // it fizzles after this long
+	Pierce        int     // targets it goes through before it is spent, 0 stops at the first
+
+	Damage      float64
+	KnockbackX  float64
+	KnockbackY  float64
+	BlastRadius float64    // explodes on impact or when it runs out, hurting everything this close
+	Status      StatusKind // effect it leaves on what it hurts
+
+	Color color.RGBA // drawn as a box until it gets art
+}
+
+// ProjectileProperty on a ranged enemy object names the kind it shoots
+const ProjectileProperty = "projectile"
+
+// ProjectileKinds is the projectile table, spawnProjectile frame events and
+// ranged enemies name their kind from it
+var ProjectileKinds = map[string]ProjectileKind{
+	"arrow": {Width: 24, Height: 4, Speed: 700, Gravity: 0.2, LifetimeSec: 2, Damage: 8, KnockbackX: 150, KnockbackY: 80, Color: color.RGBA{200, 170, 120, 255}},
+	"dart":  {Width: 12, Height: 3, Speed: 800, Gravity: 0.1, LifetimeSec: 2, Damage: 3, KnockbackX: 50, KnockbackY: 20, Status: StatusPoison, Color: color.RGBA{120, 200, 90, 255}},
+	"bomb":  {Width: 14, Height: 14, Speed: 300, Lift: 450, Gravity: 1, LifetimeSec: 3, Damage: 25, KnockbackX: 350, KnockbackY: 350, BlastRadius: 80, Status: StatusBurn, Color: color.RGBA{60, 60, 60, 255}},
+	"bolt":  {Width: 16, Height: 16, Speed: 500, LifetimeSec: 1.5, Pierce: 2, Damage: 12, KnockbackX: 200, KnockbackY: 100, Status: StatusFreeze, Color: color.RGBA{120, 160, 255, 255}},
+	"slash": {Width: 40, Height: 50, Speed: 600, LifetimeSec: 0.5, Pierce: 5, Damage: 20, KnockbackX: 300, KnockbackY: 150, Color: color.RGBA{255, 255, 255, 180}},
+}
+
+// ---------------- projectile ----------------
+type Projectile struct {
+	Kind string
+	Team Team
+	ID   int // hits from one projectile count as one swing, see Hurtable
+
+	Pos, PrevPos Position // top left, now and at the previous step (for interpolation)
+	VelX, VelY   float64
+	Age          float64 // seconds since it was fired
+	PiercesLeft  int
+	Alive        bool
+
+	spec ProjectileKind // ProjectileKinds[Kind], looked up once at spawn
+	hits []Collider     // targets it already hurt, a projectile hurts each once
+}
+
+// GetBounds implements Collider so projectiles can be tracked in the quadtree
+func (p *Projectile) GetBounds() AABB {
+	return AABB{X: p.Pos.X, Y: p.Pos.Y, Width: p.spec.Width, Height: p.spec.Height}
+}
+
+// Spec is the kind the projectile was fired as
+func (p *Projectile) Spec() ProjectileKind {
+	return p.spec
+}
+
+// swing is the swing number a projectile hits with, negative so it never
+// matches one of the player's own swings
+func (p *Projectile) swing() int {
+	return -p.ID
+}
+
+func (p *Projectile) hasHit(c Collider) bool {
+	for _, h := range p.hits {
+		if h == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Projectile) hit(sourceX float64) Hit {
+	return Hit{Damage: p.spec.Damage, SourceX: sourceX, KnockbackX: p.spec.KnockbackX, KnockbackY: p.spec.KnockbackY, Status: p.spec.Status}
+}
+
+// ---------------- projectiles ----------------
+// Projectiles is a fixed size pool of projectiles. The quadtree holds
+// pointers into it, so it never grows past MaxProjectiles and spent
+// projectiles are recycled instead.
+type Projectiles struct {
+	pool   []Projectile
+	free   []int // indices of spent projectiles in pool
+	nextID int
+	live   int
+	nearby []Collider // reused for quadtree queries, hundreds of them a step
+}
+
+func NewProjectiles() *Projectiles {
+	return &Projectiles{pool: make([]Projectile, 0, MaxProjectiles)}
+}
+
+// Live is how many projectiles are in flight
+func (pp *Projectiles) Live() int {
+	return pp.live
+}
+
+// Spawn fires a projectile of kind from the front of the shooter's body,
+// towards where it faces. It reports false for an unknown kind or a full pool.
+func (pp *Projectiles) Spawn(qt *DynamicQuadtree, kind string, team Team, shooter AABB, flipX bool) bool {
+	k, ok := ProjectileKinds[kind]
+	if !ok {
+		return false
+	}
+
+	var p *Projectile
+	switch {
+	case len(pp.free) > 0:
+		p = &pp.pool[pp.free[len(pp.free)-1]]
+		pp.free = pp.free[:len(pp.free)-1]
+	case len(pp.pool) < cap(pp.pool):
+		pp.pool = append(pp.pool, Projectile{})
+		p = &pp.pool[len(pp.pool)-1]
+	default:
+		return false
+	}
+
+	dir := 1.0
+	pos := Position{X: shooter.X + shooter.Width, Y: shooter.Y + shooter.Height*muzzleHeight - k.Height/2}
+	if flipX {
+		dir = -1
+		pos.X = shooter.X - k.Width
+	}
+	pp.nextID++
+	*p = Projectile{
+		Kind:        kind,
+		Team:        team,
+		ID:          pp.nextID,
+		Pos:         pos,
+		PrevPos:     pos,
+		VelX:        dir * k.Speed,
+		VelY:        -k.Lift,
+		PiercesLeft: k.Pierce,
+		Alive:       true,
+		spec:        k,
+		hits:        p.hits[:0],
+	}
+	pp.live++
+	if qt != nil {
+		qt.Insert(p)
+	}
+	return true
+}
+
+// Update moves every projectile one step of dt seconds, lands it on whatever
+// it reaches and retires the spent ones. Hits on the player's foes come back
+// as strikes.
+func (pp *Projectiles) Update(qt *DynamicQuadtree, player *PlayerRuntime, dt float64) []Strike {
+	var strikes []Strike
+	for i := range pp.pool {
+		p := &pp.pool[i]
+		if !p.Alive {
+			continue
+		}
+		k := &p.spec
+
+		p.Age += dt
+		if p.Age >= k.LifetimeSec {
+			strikes = pp.explode(qt, p, player, strikes)
+			pp.retire(qt, i)
+			continue
+		}
+
+		p.PrevPos = p.Pos
+		p.VelY = math.Min(p.VelY+k.Gravity*GravityScale*100*dt, MaxFallSpeed)
+		p.Pos.X += p.VelX * dt
+		p.Pos.Y += p.VelY * dt
+
+		// everything it passed through this step, fast ones would skip over thin targets otherwise
+		prev := AABB{X: p.PrevPos.X, Y: p.PrevPos.Y, Width: k.Width, Height: k.Height}
+		swept := prev.Union(p.GetBounds())
+
+		spent := false
+		pp.nearby = qt.RetrieveAppend(pp.nearby[:0], swept)
+		for _, obj := range pp.nearby {
+			if _, ok := obj.(*Projectile); ok {
+				continue // projectiles fly through each other
+			}
+			if plat, ok := solidPlatform(obj); ok && !plat.IsOneWay() && plat.blocks(p.GetBounds()) {
+				spent = true
+				break
+			}
+			if !obj.GetBounds().Intersects(swept) || p.hasHit(obj) {
+				continue
+			}
+			var landed bool
+			if landed, strikes = pp.land(p, obj, swept, player, strikes); !landed {
+				continue
+			}
+			if k.BlastRadius > 0 || p.PiercesLeft == 0 {
+				spent = true
+				break
+			}
+			p.PiercesLeft--
+		}
+
+		if spent {
+			strikes = pp.explode(qt, p, player, strikes)
+			pp.retire(qt, i)
+			continue
+		}
+		qt.Update(p)
+	}
+	return strikes
+}
+
+// land hurts obj with p if it is on the other team and can take the hit,
+// and reports whether it did. Anything it can't hurt, like the dead or the
+// dodging, it flies through.
+func (pp *Projectiles) land(p *Projectile, obj Collider, box AABB, player *PlayerRuntime, strikes []Strike) (bool, []Strike) {
+	sourceX := p.PrevPos.X + p.spec.Width/2
+	switch p.Team {
+	case TeamPlayer:
+		target, ok := obj.(Hurtable)
+		if !ok || !Overlaps([]AABB{box}, target.Hurtboxes()) {
+			return false, strikes
+		}
+		damage, killed := target.TakePlayerHit(p.hit(sourceX), p.swing())
+		if damage <= 0 && !killed {
+			return false, strikes
+		}
+		p.hits = append(p.hits, obj)
+		combo, _ := player.comboHit(p.swing())
+		return true, append(strikes, Strike{Target: target, Damage: damage, Killed: killed, Combo: combo})
+
+	case TeamEnemy:
+		if player == nil || obj != Collider(player) || !Overlaps([]AABB{box}, player.Hurtboxes()) {
+			return false, strikes
+		}
+		if player.TakeHit(p.hit(sourceX)) == HitMissed {
+			return false, strikes
+		}
+		p.hits = append(p.hits, obj)
+		return true, strikes
+	}
+	return false, strikes
+}
+
+// explode hurts everything of the other team within the blast radius of a
+// projectile that has one, but what it hit directly
+func (pp *Projectiles) explode(qt *DynamicQuadtree, p *Projectile, player *PlayerRuntime, strikes []Strike) []Strike {
+	k := &p.spec
+	if k.BlastRadius <= 0 {
+		return strikes
+	}
+	b := p.GetBounds()
+	cx, cy := b.X+b.Width/2, b.Y+b.Height/2
+	blast := AABB{X: cx - k.BlastRadius, Y: cy - k.BlastRadius, Width: 2 * k.BlastRadius, Height: 2 * k.BlastRadius}
+	hit := p.hit(cx)
+
+	switch p.Team {
+	case TeamPlayer:
+		for _, obj := range qt.Retrieve(blast) {
+			target, ok := obj.(Hurtable)
+			if !ok || p.hasHit(obj) || !Overlaps([]AABB{blast}, target.Hurtboxes()) {
+				continue
+			}
+			damage, killed := target.TakePlayerHit(hit, p.swing())
+			if damage > 0 || killed {
+				combo, _ := player.comboHit(p.swing())
+				strikes = append(strikes, Strike{Target: target, Damage: damage, Killed: killed, Combo: combo})
+			}
+		}
+	case TeamEnemy:
+		if player != nil && !p.hasHit(player) && Overlaps([]AABB{blast}, player.Hurtboxes()) {
+			player.TakeHit(hit)
+		}
+	}
+	return strikes
+}
+
+// retire takes the projectile at i out of play and frees its slot
+func (pp *Projectiles) retire(qt *DynamicQuadtree, i int) {
+	pp.pool[i].Alive = false
+	if qt != nil {
+		qt.Remove(&pp.pool[i])
+	}
+	pp.free = append(pp.free, i)
+	pp.live--
+}
+
+// Clear retires every projectile, eg: on respawn
+func (pp *Projectiles) Clear(qt *DynamicQuadtree) {
+	for i := range pp.pool {
+		if pp.pool[i].Alive {
+			pp.retire(qt, i)
+		}
+	}
+}
+
+// Each calls fn with every projectile in flight, in pool order
+func (pp *Projectiles) Each(fn func(p *Projectile)) {
+	for i := range pp.pool {
+		if pp.pool[i].Alive {
+			fn(&pp.pool[i])
+		}
+	}
+}
+
+// checkProjectiles makes sure every ranged enemy of the level shoots a known kind
+func (l *LevelData) checkProjectiles() error {
+	for _, spawn := range l.Spawns {
+		kind := spawn.Properties[ProjectileProperty]
+		if spawn.Kind != EntityEnemyRanged || kind == "" {
+			continue
+		}
+		if _, ok := ProjectileKinds[kind]; !ok {
+			return fmt.Errorf("enemy at (%.0f, %.0f): unknown %s %q", spawn.X, spawn.Y, ProjectileProperty, kind)
+		}
+	}
+	return nil
+}
+
+// blocks reports whether the platform stops a projectile at box, slopes only
+// below their surface
+func (p *Platform) blocks(box AABB) bool {
+	if !box.Intersects(p.GetBounds()) {
+		return false
+	}
+	if !p.IsSlope() {
+		return true
+	}
+	return box.Y+box.Height > p.FloorY(box.X+box.Width/2)
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// dummy is a Hurtable target that never moves or dies
+type dummy struct {
+	box    AABB
+	swings []int // swings that hurt it, in order
+}
+
+func (d *dummy) GetBounds() AABB   { return d.box }
+func (d *dummy) Hurtboxes() []AABB { return []AABB{d.box} }
+
+func (d *dummy) TakePlayerHit(hit Hit, swing int) (float64, bool) {
+	for _, s := range d.swings {
+		if s == swing {
+			return 0, false
+		}
+	}
+	d.swings = append(d.swings, swing)
+	return hit.Damage, false
+}
+
+// emptyTree is a quadtree with nothing in it
+func emptyTree() *DynamicQuadtree {
+	return NewDynamicQuadtree(AABB{X: -2000, Y: -2000, Width: 6000, Height: 4000})
+}
+
+// flyProjectiles steps pp for up to seconds, until none are left, and
+// returns every strike
+func flyProjectiles(pp *Projectiles, qt *DynamicQuadtree, player *PlayerRuntime, seconds float64) []Strike {
+	var strikes []Strike
+	for i := 0; i < int(seconds*TickRate) && pp.Live() > 0; i++ {
+		strikes = append(strikes, pp.Update(qt, player, 1.0/TickRate)...)
+	}
+	return strikes
+}
+
+// onlyProjectile is the one projectile in flight
+func onlyProjectile(t *testing.T, pp *Projectiles) *Projectile {
+	t.Helper()
+	if pp.Live() != 1 {
+		t.Fatalf("%d projectiles in flight, want 1", pp.Live())
+	}
+	var only *Projectile
+	pp.Each(func(p *Projectile) { only = p })
+	return only
+}
+
+func TestProjectileSpawn(t *testing.T) {
+	shooter := AABB{X: 100, Y: 200, Width: 40, Height: 50}
+	arrow := ProjectileKinds["arrow"]
+	tests := []struct {
+		name     string
+		flipX    bool
+		wantX    float64
+		wantVelX float64
+	}{
+		{"facing right", false, 140, arrow.Speed},
+		{"facing left", true, 100 - arrow.Width, -arrow.Speed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pp := NewProjectiles()
+			if !pp.Spawn(nil, "arrow", TeamEnemy, shooter, tt.flipX) {
+				t.Fatal("spawn failed")
+			}
+			p := onlyProjectile(t, pp)
+			wantY := 200 + 50*muzzleHeight - arrow.Height/2
+			if p.Pos.X != tt.wantX || p.Pos.Y != wantY {
+				t.Fatalf("spawned at (%v, %v), want (%v, %v)", p.Pos.X, p.Pos.Y, tt.wantX, wantY)
+			}
+			if p.VelX != tt.wantVelX {
+				t.Fatalf("VelX %v, want %v", p.VelX, tt.wantVelX)
+			}
+		})
+	}
+
+	pp := NewProjectiles()
+	if pp.Spawn(nil, "fireball", TeamEnemy, shooter, false) {
+		t.Fatal("spawned an unknown kind")
+	}
+	if pp.Live() != 0 {
+		t.Fatalf("%d projectiles after a failed spawn, want 0", pp.Live())
+	}
+}
+
+func TestProjectilePoolFull(t *testing.T) {
+	pp := NewProjectiles()
+	for i := 0; i < MaxProjectiles; i++ {
+		if !pp.Spawn(nil, "dart", TeamEnemy, AABB{}, false) {
+			t.Fatalf("spawn %d failed under the cap", i)
+		}
+	}
+	if pp.Spawn(nil, "dart", TeamEnemy, AABB{}, false) {
+		t.Fatal("spawned past MaxProjectiles")
+	}
+
+	// cleared slots are reused
+	pp.Clear(nil)
+	if pp.Live() != 0 {
+		t.Fatalf("%d projectiles after Clear, want 0", pp.Live())
+	}
+	if !pp.Spawn(nil, "dart", TeamEnemy, AABB{}, false) {
+		t.Fatal("spawn after Clear failed")
+	}
+}
+
+func TestProjectileLifetime(t *testing.T) {
+	pp := NewProjectiles()
+	qt := emptyTree()
+	pp.Spawn(qt, "bolt", TeamEnemy, AABB{Width: 10, Height: 10}, false)
+
+	lifetime := ProjectileKinds["bolt"].LifetimeSec
+	ticks := 0
+	for pp.Live() > 0 && ticks < 10*TickRate {
+		pp.Update(qt, nil, 1.0/TickRate)
+		ticks++
+	}
+	// the summed step times can fall a hair short of the lifetime, costing a tick
+	if want := int(lifetime * TickRate); ticks < want || ticks > want+1 {
+		t.Fatalf("bolt lived %d ticks, want %d", ticks, want)
+	}
+	if n := len(qt.Retrieve(AABB{X: -2000, Y: -2000, Width: 6000, Height: 4000})); n != 0 {
+		t.Fatalf("%d objects left in the quadtree, want 0", n)
+	}
+}
+
+func TestProjectileStopsAtWall(t *testing.T) {
+	tests := []struct {
+		name    string
+		shape   PlatformShape
+		stopped bool
+	}{
+		{"solid wall", ShapeSolid, true},
+		{"one-way platform", ShapeOneWay, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pp := NewProjectiles()
+			qt := emptyTree()
+			qt.Insert(&Platform{X: 300, Y: -200, Width: 40, Height: 400, TileInfo: Tile{TileType: Rock}, Shape: tt.shape})
+			pp.Spawn(qt, "bolt", TeamEnemy, AABB{Width: 10, Height: 10}, false)
+
+			// the bolt reaches the wall in about half a second, and lives for one and a half
+			flyProjectiles(pp, qt, nil, 1)
+			if stopped := pp.Live() == 0; stopped != tt.stopped {
+				t.Fatalf("stopped = %v, want %v", stopped, tt.stopped)
+			}
+		})
+	}
+}
+
+func TestEnemyProjectileHitsPlayer(t *testing.T) {
+	player, qt := groundedPlayer(t, Rock)
+	qt.Insert(player)
+	health := player.Combat.Health
+	shooter := AABB{X: player.Pos.X - 200, Y: player.Pos.Y, Width: PlayerWidth, Height: PlayerHeight}
+
+	pp := NewProjectiles()
+	pp.Spawn(qt, "arrow", TeamEnemy, shooter, false)
+	flyProjectiles(pp, qt, player, 1)
+	if pp.Live() != 0 {
+		t.Fatal("the arrow did not stop at the player")
+	}
+	if want := health - ProjectileKinds["arrow"].Damage; player.Combat.Health != want {
+		t.Fatalf("health %v, want %v", player.Combat.Health, want)
+	}
+	if !player.State.IsDamaged() {
+		t.Fatal("player is not in the hurt state")
+	}
+
+	// during the i-frames the next arrow flies through
+	health = player.Combat.Health
+	pp.Spawn(qt, "arrow", TeamEnemy, shooter, false)
+	for i := 0; i < TickRate/2; i++ {
+		pp.Update(qt, player, 1.0/TickRate)
+	}
+	if player.Combat.Health != health {
+		t.Fatal("an arrow hurt the player during the i-frames")
+	}
+	if p := onlyProjectile(t, pp); p.Pos.X < player.Pos.X+PlayerWidth {
+		t.Fatalf("arrow at x %v, want it past the player", p.Pos.X)
+	}
+}
+
+func TestProjectilePierce(t *testing.T) {
+	qt := emptyTree()
+	var targets []*dummy
+	for i := 0; i < 5; i++ {
+		d := &dummy{box: AABB{X: 100 + float64(i)*100, Y: -20, Width: 20, Height: 60}}
+		targets = append(targets, d)
+		qt.Insert(d)
+	}
+
+	pp := NewProjectiles()
+	pp.Spawn(qt, "bolt", TeamPlayer, AABB{Width: 10, Height: 10}, false)
+	strikes := flyProjectiles(pp, qt, nil, 2)
+
+	// it goes through Pierce targets and stops in the next one
+	want := ProjectileKinds["bolt"].Pierce + 1
+	if len(strikes) != want {
+		t.Fatalf("%d strikes, want %d", len(strikes), want)
+	}
+	for i, d := range targets {
+		if hit := len(d.swings) == 1; hit != (i < want) {
+			t.Errorf("target %d hurt %d times", i, len(d.swings))
+		}
+	}
+}
+
+func TestProjectileBlast(t *testing.T) {
+	qt := emptyTree()
+	qt.Insert(&Platform{X: -1000, Y: 500, Width: 3000, Height: LevelTileHeight, TileInfo: Tile{TileType: Rock}})
+	near := &dummy{box: AABB{X: 150, Y: 460, Width: 20, Height: 40}}
+	far := &dummy{box: AABB{X: 400, Y: 460, Width: 20, Height: 40}}
+	qt.Insert(near)
+	qt.Insert(far)
+
+	// drop the bomb straight down next to the near target
+	pp := NewProjectiles()
+	pp.Spawn(qt, "bomb", TeamPlayer, AABB{X: 80, Y: 300, Width: 20, Height: 20}, false)
+	bomb := onlyProjectile(t, pp)
+	bomb.VelX, bomb.VelY = 0, 0
+
+	strikes := flyProjectiles(pp, qt, nil, 2)
+	if pp.Live() != 0 {
+		t.Fatal("the bomb did not go off on the floor")
+	}
+	if len(strikes) != 1 || strikes[0].Target != Hurtable(near) {
+		t.Fatalf("strikes %+v, want the near target only", strikes)
+	}
+	if len(far.swings) != 0 {
+		t.Fatal("the blast reached the far target")
+	}
+}
+
+func TestCheckProjectiles(t *testing.T) {
+	level := LevelData{Spawns: []EntitySpawn{
+		{Kind: EntityEnemyRanged, Properties: map[string]string{ProjectileProperty: "bolt"}},
+		{Kind: EntityEnemyRanged},
+	}}
+	if err := level.checkProjectiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	level.Spawns = append(level.Spawns, EntitySpawn{Kind: EntityEnemyRanged, X: 64, Y: 32, Properties: map[string]string{ProjectileProperty: "fireball"}})
+	err := level.checkProjectiles()
+	if err == nil || !strings.Contains(err.Error(), `"fireball"`) {
+		t.Fatalf("err = %v, want the unknown kind named", err)
+	}
+}
@@ -267,3 +267,9 @@ func (dq *DynamicQuadtree) Remove(obj Collider) bool {
 func (dq *DynamicQuadtree) Retrieve(rect AABB) []Collider {
 	return dq.Root.Retrieve(nil, rect)
 }
+
+// RetrieveAppend appends the potential colliders for rect to dst, so hot
+// loops can reuse one slice
+func (dq *DynamicQuadtree) RetrieveAppend(dst []Collider, rect AABB) []Collider {
+	return dq.Root.Retrieve(dst, rect)
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ------------------------ platform shapes ------------------------
+// PlatformShape is how a solid tile collides: a full block, a one-way ledge
+// that can be jumped through from below, or a slope. The 22.5° slopes take two
+// tiles to rise one, so they come as a low and a high half.
+type PlatformShape string
+
+const (
+	ShapeSolid        PlatformShape = "" // full block, the default
+	ShapeOneWay       PlatformShape = "oneway"
+	ShapeSlope45R     PlatformShape = "slope45r"      // rises to the right
+	ShapeSlope45L     PlatformShape = "slope45l"      // rises to the left
+	ShapeSlope22RLow  PlatformShape = "slope22r_low"  // 22.5° rising to the right, first tile
+	ShapeSlope22RHigh PlatformShape = "slope22r_high" // 22.5° rising to the right, second tile
+	ShapeSlope22LHigh PlatformShape = "slope22l_high" // 22.5° rising to the left, first tile
+	ShapeSlope22LLow  PlatformShape = "slope22l_low"  // 22.5° rising to the left, second tile
+
+	// ShapeSeparator splits a tile type from its shape in level formats, eg: "wood/oneway"
+	ShapeSeparator = "/"
+
+	DropThroughMs = 200 // how long one-way platforms are ignored after down+jump
+)
+
+// slopeHeights are the surface heights at the left and right edge of each
+// slope, as a share of the tile height measured from the bottom
+var slopeHeights = map[PlatformShape][2]float64{
+	ShapeSlope45R:     {0, 1},
+	ShapeSlope45L:     {1, 0},
+	ShapeSlope22RLow:  {0, 0.5},
+	ShapeSlope22RHigh: {0.5, 1},
+	ShapeSlope22LHigh: {1, 0.5},
+	ShapeSlope22LLow:  {0.5, 0},
+}
+
+// IsPlatformShape reports whether s is a shape levels may use
+func IsPlatformShape(s PlatformShape) bool {
+	_, slope := slopeHeights[s]
+	return slope || s == ShapeSolid || s == ShapeOneWay
+}
+
+// IsSlope reports whether the shape is one of the slopes
+func (s PlatformShape) IsSlope() bool {
+	_, ok := slopeHeights[s]
+	return ok
+}
+
+// SurfaceHeights are the slope heights at the left and right edge as a share
+// of the tile height, 1 and 1 for anything flat
+func (s PlatformShape) SurfaceHeights() (left, right float64) {
+	if h, ok := slopeHeights[s]; ok {
+		return h[0], h[1]
+	}
+	return 1, 1
+}
+
+// ParseTileShape splits a "tile/shape" value from a level format, a plain
+// tile type is a full block
+func ParseTileShape(value string) (TileType, PlatformShape, error) {
+	tile, shape, _ := strings.Cut(value, ShapeSeparator)
+	if !IsPlatformShape(PlatformShape(shape)) {
+		return TileType(tile), ShapeSolid, fmt.Errorf("unknown shape %q", shape)
+	}
+	return TileType(tile), PlatformShape(shape), nil
+}
+
+// IsSlope reports whether the platform is a slope tile
+func (p *Platform) IsSlope() bool {
+	return p.Shape.IsSlope()
+}
+
+// IsOneWay reports whether the platform only blocks from above
+func (p *Platform) IsOneWay() bool {
+	return p.Shape == ShapeOneWay
+}
+
+// FloorY is the height of the walkable surface at world x, the top of the
+// platform for anything but a slope
+func (p *Platform) FloorY(x float64) float64 {
+	if !p.IsSlope() {
+		return p.Y
+	}
+	left, right := p.Shape.SurfaceHeights()
+	t := math.Max(0, math.Min(1, (x-p.X)/p.Width))
+	return p.Y + p.Height*(1-(left+(right-left)*t))
+}
+
+// slopeFace is the part of a slope that blocks a body moving sideways by dx:
+// the tile below the surface at the edge it is walked into from. The low edge
+// of a slope has no face, so bodies walk onto it instead.
+func (p *Platform) slopeFace(dx float64) AABB {
+	faceX := p.X // walked into from the left
+	if dx < 0 {
+		faceX = p.X + p.Width
+	}
+	top := p.FloorY(faceX)
+	return AABB{X: p.X, Y: top, Width: p.Width, Height: p.Y + p.Height - top}
+}
+
+// ---------------- drop through ----------------
+
+// dropThrough lets the player fall through the one-way platform it stands on
+// with down+jump. It eats the jump press so it doesn't also jump.
+func (player *PlayerRuntime) dropThrough(inputState *InputState, canDrop bool) bool {
+	if !canDrop || !inputState.JumpJustPressed || inputState.Direction.UpDown >= 0 || !player.OnOneWay() {
+		return false
+	}
+	player.DropThroughMs = DropThroughMs
+	player.OnGround = false
+	player.Physics.CoyoteMs = 0
+	player.Physics.JumpBufferLeft = 0
+	player.State.SetPlayerState(int(PlayerStateFalling))
+	return true
+}
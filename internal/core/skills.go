@@ -1 +1,108 @@
 package core
+
+// ---------------- attacks ----------------
+// an attack is any animation whose frame data has hitboxes, what it deals
+// and where it reaches come from assets/framedata.json
+
+// Hurtable is anything in the quadtree the player's attacks can hit
+type Hurtable interface {
+	Collider
+	// Hurtboxes are where it can be hurt right now
+	Hurtboxes() []AABB
+	// TakePlayerHit applies a hit from the player's swing number swing. A
+	// swing hurts each target once. It returns the damage taken and whether
+	// the hit killed it.
+	TakePlayerHit(hit Hit, swing int) (damage float64, killed bool)
+}
+
+// Strike is one target a swing landed on
+type Strike struct {
+	Target Hurtable
+	Damage float64
+	Killed bool
+	Combo  int // the combo counter with this hit
+	Bonus  int // score of the combo chain this hit finished
+}
+
+// attack is the frame data of the attack the player is in, nil if it isn't attacking
+func (player *PlayerRuntime) attack() *FrameData {
+	anim, ok := player.Animations[player.State.GetPlayerState()]
+	if !ok || !anim.Frames.HasHitboxes() {
+		return nil
+	}
+	return anim.Frames
+}
+
+// startGroundAttack goes into the ground attack whose button is down, specials
+// first. An attack refused for lack of power leaves the next one a go.
+func (player *PlayerRuntime) startGroundAttack(skills Skills) bool {
+	return player.startFirstAttack([]Pair[bool, PlayerStateType]{
+		{skills.SpecialAttack1, PlayerStateSpecialAttack1},
+		{skills.SpecialAttack2, PlayerStateSpecialAttack2},
+		{skills.SpecialAttack3, PlayerStateSpecialAttack3},
+		{skills.SpecialAttack4, PlayerStateSpecialAttack4},
+		{skills.WeakAttack, PlayerStateWeakAttack},
+		{skills.StrongAttack, PlayerStateStrongAttack},
+	})
+}
+
+// startAirAttack goes into the air attack whose button is down
+func (player *PlayerRuntime) startAirAttack(skills Skills) bool {
+	return player.startFirstAttack([]Pair[bool, PlayerStateType]{
+		{skills.WeakAttack, PlayerStateWeakAttackInAir},
+		{skills.StrongAttack, PlayerStateStrongAttackInAir},
+	})
+}
+
+// startFirstAttack starts the first attack that is pressed and can be paid for
+func (player *PlayerRuntime) startFirstAttack(attacks []Pair[bool, PlayerStateType]) bool {
+	for _, atk := range attacks {
+		if atk.First && player.startAttack(atk.Second) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackSwing counts a new swing every time the player goes into an attack,
+// a combo step that plays the same attack again counts its own
+func (player *PlayerRuntime) trackSwing() {
+	if player.attack() != nil && player.PreviousState.CurrentState != player.State.CurrentState {
+		player.Swing++
+	}
+}
+
+// AttackHitboxes are the world boxes the current attack hurts on the frame
+// the player shows, none outside its active frames
+func (player *PlayerRuntime) AttackHitboxes() []AABB {
+	return player.CurrentFrame().HitboxesOn(player.GetBounds(), player.FlipX)
+}
+
+// Strike lands the current attack on everything hurtable it overlaps in qt
+func (player *PlayerRuntime) Strike(qt *DynamicQuadtree) []Strike {
+	atk := player.attack()
+	boxes := player.AttackHitboxes()
+	if atk == nil || len(boxes) == 0 || qt == nil {
+		return nil
+	}
+	hit := atk.Hit(player.Pos.X + player.Width/2)
+	hit.Damage *= player.Statuses.StrengthScale()
+
+	var strikes []Strike
+	for _, box := range boxes {
+		for _, obj := range qt.Retrieve(box) {
+			target, ok := obj.(Hurtable)
+			if !ok || !Overlaps([]AABB{box}, target.Hurtboxes()) {
+				continue
+			}
+			// a swing hurts each target once, however many of its boxes overlap
+			damage, killed := target.TakePlayerHit(hit, player.Swing)
+			if damage > 0 || killed {
+				player.Combat.GainPower(atk.PowerGain)
+				combo, bonus := player.comboHit(player.Swing)
+				strikes = append(strikes, Strike{Target: target, Damage: damage, Killed: killed, Combo: combo, Bonus: bonus})
+			}
+		}
+	}
+	return strikes
+}
@@ -0,0 +1,180 @@
+package core
+
+// ------------------------ status effects ------------------------
+// Status effects are what lingers after a hit or a step on lava: damage over
+// time and scaled stats until they run out. Players and enemies each carry
+// a Statuses, hits, projectiles and materials name the effect they apply.
+
+type StatusKind string
+
+const (
+	StatusBurn   StatusKind = "burn"
+	StatusFreeze StatusKind = "freeze"
+	StatusPoison StatusKind = "poison"
+	StatusStun   StatusKind = "stun"
+)
+
+// StackRule is what applying an effect that is already on does
+type StackRule int
+
+const (
+	StackRefresh   StackRule = iota // the duration starts over
+	StackIntensity                  // another stack, up to MaxStacks, ticks hurt per stack, the duration starts over
+	StackIgnore                     // nothing until it runs out, eg: no stun locks
+)
+
+// StatusDef is how one kind of status effect behaves
+type StatusDef struct {
+	DurationSec float64
+	Stacking    StackRule
+	MaxStacks   int
+
+	TickSec    float64 // time between ticks of damage
+	TickDamage float64 // per tick and per stack
+
+	// stat multipliers while it is on
+	SpeedScale    float64
+	StrengthScale float64
+	IQScale       float64
+	Stunned       bool // no acting at all
+
+	Tint [3]float32 // r, g, b the sprite is scaled by while it is on
+}
+
+// StatusKinds is the status effect table
+var StatusKinds = map[StatusKind]StatusDef{
+	StatusBurn:   {DurationSec: 3, Stacking: StackRefresh, MaxStacks: 1, TickSec: 0.5, TickDamage: 4, SpeedScale: 1, StrengthScale: 1, IQScale: 1, Tint: [3]float32{1, 0.55, 0.4}},
+	StatusFreeze: {DurationSec: 2, Stacking: StackRefresh, MaxStacks: 1, SpeedScale: 0.4, StrengthScale: 0.8, IQScale: 0.5, Tint: [3]float32{0.55, 0.75, 1}},
+	StatusPoison: {DurationSec: 5, Stacking: StackIntensity, MaxStacks: 5, TickSec: 1, TickDamage: 2, SpeedScale: 1, StrengthScale: 0.9, IQScale: 1, Tint: [3]float32{0.6, 1, 0.5}},
+	StatusStun:   {DurationSec: 1, Stacking: StackIgnore, MaxStacks: 1, SpeedScale: 0, StrengthScale: 1, IQScale: 1, Stunned: true, Tint: [3]float32{1, 1, 0.5}},
+}
+
+// StatusEffect is one effect on a body
+type StatusEffect struct {
+	Kind     StatusKind
+	TimeLeft float64 // seconds until it wears off
+	Stacks   int
+	TickLeft float64 // seconds until the next tick of damage
+
+	def StatusDef // StatusKinds[Kind], looked up once when applied
+}
+
+// ---------------- statuses ----------------
+// Statuses are the effects on one body, in the order they were applied
+type Statuses struct {
+	Active []StatusEffect
+}
+
+// Apply puts an effect of kind on, following its stacking rule. Unknown
+// kinds, and the empty one hits without an effect carry, do nothing.
+func (s *Statuses) Apply(kind StatusKind) {
+	def, ok := StatusKinds[kind]
+	if !ok {
+		return
+	}
+	if e := s.find(kind); e != nil {
+		switch def.Stacking {
+		case StackIntensity:
+			e.Stacks = min(e.Stacks+1, def.MaxStacks)
+			e.TimeLeft = def.DurationSec
+		case StackRefresh:
+			e.TimeLeft = def.DurationSec
+		}
+		return
+	}
+	s.Active = append(s.Active, StatusEffect{Kind: kind, TimeLeft: def.DurationSec, Stacks: 1, TickLeft: def.TickSec, def: def})
+}
+
+func (s *Statuses) find(kind StatusKind) *StatusEffect {
+	for i := range s.Active {
+		if s.Active[i].Kind == kind {
+			return &s.Active[i]
+		}
+	}
+	return nil
+}
+
+// Has reports whether an effect of kind is on
+func (s *Statuses) Has(kind StatusKind) bool {
+	return s.find(kind) != nil
+}
+
+// Update runs the effects down by dt seconds, drops the ones that wore off
+// and returns the damage their ticks dealt
+func (s *Statuses) Update(dt float64) float64 {
+	damage := 0.0
+	kept := s.Active[:0]
+	for _, e := range s.Active {
+		e.TimeLeft -= dt
+		if e.def.TickSec > 0 {
+			e.TickLeft -= dt
+			for e.TickLeft <= 0 {
+				damage += e.def.TickDamage * float64(e.Stacks)
+				e.TickLeft += e.def.TickSec
+			}
+		}
+		if e.TimeLeft > 0 {
+			kept = append(kept, e)
+		}
+	}
+	s.Active = kept
+	return damage
+}
+
+// Clear takes every effect off, eg: on respawn
+func (s *Statuses) Clear() {
+	s.Active = s.Active[:0]
+}
+
+// SpeedScale multiplies the target speed, the effects on multiply together
+func (s *Statuses) SpeedScale() float64 {
+	scale := 1.0
+	for _, e := range s.Active {
+		scale *= e.def.SpeedScale
+	}
+	return scale
+}
+
+// StrengthScale multiplies the damage dealt
+func (s *Statuses) StrengthScale() float64 {
+	scale := 1.0
+	for _, e := range s.Active {
+		scale *= e.def.StrengthScale
+	}
+	return scale
+}
+
+// IQScale multiplies the decision quality of the AI
+func (s *Statuses) IQScale() float64 {
+	scale := 1.0
+	for _, e := range s.Active {
+		scale *= e.def.IQScale
+	}
+	return scale
+}
+
+// Stunned reports whether an effect on stops all acting
+func (s *Statuses) Stunned() bool {
+	for _, e := range s.Active {
+		if e.def.Stunned {
+			return true
+		}
+	}
+	return false
+}
+
+// Tint is the colour the sprite is scaled by, the tints of the effects on
+// multiplied together. It reports false with nothing on.
+func (s *Statuses) Tint() (r, g, b float32, ok bool) {
+	r, g, b = 1, 1, 1
+	for _, e := range s.Active {
+		r, g, b = r*e.def.Tint[0], g*e.def.Tint[1], b*e.def.Tint[2]
+	}
+	return r, g, b, len(s.Active) > 0
+}
+
+// updateStatuses ticks the player's status effects, a tick hurts without
+// stun or knockback and World sees to death like for any damage
+func (player *PlayerRuntime) updateStatuses(dt float64) {
+	player.Combat.TakeDamage(player.Statuses.Update(dt))
+}
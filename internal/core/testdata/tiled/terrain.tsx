@@ -0,0 +1,16 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="terrain" tilewidth="32" tileheight="32" tilecount="4" columns="2">
+ <properties>
+  <property name="material" value="stone"/>
+ </properties>
+ <tile id="0">
+  <properties>
+   <property name="material" value="grass"/>
+  </properties>
+ </tile>
+ <tile id="1">
+  <properties>
+   <property name="shape" value="oneway"/>
+  </properties>
+ </tile>
+</tileset>
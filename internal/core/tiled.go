@@ -0,0 +1,663 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ------------------------ Tiled map import ------------------------
+// Supports orthogonal, finite Tiled maps saved as JSON (.tmj) or XML (.tmx),
+// with embedded or external (.tsj / .tsx) tilesets.
+//
+// Every non-empty cell of a tile layer becomes a Platform. Its TileType comes
+// from the "material" custom property, looked up on the tile, then on the
+// layer, then on the tileset. An optional "shape" property, looked up the same
+// way, makes it a one-way platform or a slope (see PlatformShape). Objects in
+// object layers become EntitySpawns, their type (class) must be an EntityKind.
+const (
+	TiledMaterialProperty = "material"
+	TiledShapeProperty    = "shape"
+
+	tiledFlipMask = 0x0FFFFFFF // clears the flip / rotation flags Tiled stores in the top bits of a gid
+)
+
+// TiledLoader reads maps made with the Tiled editor
+type TiledLoader struct{}
+
+func (TiledLoader) Load(path string) (*LevelData, error) {
+	var (
+		m   *tiledMap
+		err error
+	)
+	if strings.EqualFold(filepath.Ext(path), ".tmx") {
+		m, err = readTMX(path)
+	} else {
+		m, err = readTMJ(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.toLevel()
+}
+
+// ---------------- common map model ----------------
+// both file formats are decoded into this before building the level
+
+type tiledMap struct {
+	Width, Height         int
+	TileWidth, TileHeight int
+	TileLayers            []tiledTileLayer
+	ObjectLayers          []tiledObjectLayer
+	Tilesets              []tiledTileset
+}
+
+type tiledTileLayer struct {
+	Name       string
+	GIDs       []uint32 // row major, Width*Height of the map
+	Properties map[string]string
+}
+
+type tiledObjectLayer struct {
+	Name       string
+	Objects    []tiledObject
+	Properties map[string]string
+}
+
+type tiledObject struct {
+	Name                string
+	Type                string
+	X, Y, Width, Height float64 // in Tiled pixels, X/Y is the top left corner
+	Properties          map[string]string
+}
+
+type tiledTileset struct {
+	FirstGID   uint32
+	TileCount  uint32                       // gids from FirstGID up to FirstGID+TileCount are its tiles
+	Tiles      map[uint32]map[string]string // local tile id -> properties
+	Properties map[string]string
+}
+
+// tileProperty looks a property of gid up on the tile, then the layer, then the tileset
+func (m *tiledMap) tileProperty(gid uint32, layer *tiledTileLayer, name string) string {
+	ts := m.tilesetFor(gid)
+	if ts != nil {
+		if v, ok := ts.Tiles[gid-ts.FirstGID][name]; ok {
+			return v
+		}
+	}
+	if v, ok := layer.Properties[name]; ok {
+		return v
+	}
+	if ts != nil {
+		return ts.Properties[name]
+	}
+	return ""
+}
+
+// tilesetFor returns the tileset with the highest firstgid not above gid
+func (m *tiledMap) tilesetFor(gid uint32) *tiledTileset {
+	var found *tiledTileset
+	for i := range m.Tilesets {
+		ts := &m.Tilesets[i]
+		if ts.FirstGID <= gid && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	return found
+}
+
+func (m *tiledMap) toLevel() (*LevelData, error) {
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return nil, errors.New("map has no tile size")
+	}
+
+	// Tiled pixels -> world units
+	scaleX := float64(LevelTileWidth) / float64(m.TileWidth)
+	scaleY := float64(LevelTileHeight) / float64(m.TileHeight)
+
+	level := &LevelData{
+		Width:  float64(m.Width * LevelTileWidth),
+		Height: float64(m.Height * LevelTileHeight),
+	}
+
+	for li := range m.TileLayers {
+		layer := &m.TileLayers[li]
+		if len(layer.GIDs) != m.Width*m.Height {
+			return nil, fmt.Errorf("layer %q has %d tiles, want %d", layer.Name, len(layer.GIDs), m.Width*m.Height)
+		}
+
+		// resolve every cell first so the bottom tile check can look upwards
+		types := make([]TileType, len(layer.GIDs))
+		shapes := make([]PlatformShape, len(layer.GIDs))
+		for i, raw := range layer.GIDs {
+			gid := raw & tiledFlipMask
+			if gid == 0 {
+				types[i] = Empty
+				continue
+			}
+			if ts := m.tilesetFor(gid); ts == nil || gid-ts.FirstGID >= ts.TileCount {
+				return nil, fmt.Errorf("layer %q cell (%d, %d): tile %d is in no tileset", layer.Name, i%m.Width, i/m.Width, gid)
+			}
+			material := TileType(m.tileProperty(gid, layer, TiledMaterialProperty))
+			if material == "" {
+				return nil, fmt.Errorf("layer %q cell (%d, %d): tile %d has no %q property", layer.Name, i%m.Width, i/m.Width, gid, TiledMaterialProperty)
+			}
+			if !IsTileType(material) {
+				return nil, fmt.Errorf("layer %q cell (%d, %d): unknown material %q", layer.Name, i%m.Width, i/m.Width, material)
+			}
+			types[i] = material
+
+			shape := PlatformShape(m.tileProperty(gid, layer, TiledShapeProperty))
+			if !IsPlatformShape(shape) {
+				return nil, fmt.Errorf("layer %q cell (%d, %d): unknown shape %q", layer.Name, i%m.Width, i/m.Width, shape)
+			}
+			shapes[i] = shape
+		}
+
+		for y := 0; y < m.Height; y++ {
+			prevPlat := Platform{TileInfo: Tile{TileType: Empty}}
+			for x := 0; x < m.Width; x++ {
+				tileType := types[y*m.Width+x]
+				if tileType != Empty {
+					solidAbove := y > 0 && types[(y-1)*m.Width+x] != Empty
+					level.Platforms = append(level.Platforms, getTileInfo(x, y, tileType, shapes[y*m.Width+x], solidAbove, prevPlat))
+					prevPlat = level.Platforms[len(level.Platforms)-1]
+				}
+				prevPlat.TileInfo.TileType = tileType
+			}
+		}
+	}
+
+	for _, layer := range m.ObjectLayers {
+		for _, obj := range layer.Objects {
+			if obj.Type == "" {
+				// untyped objects are editor annotations
+				continue
+			}
+			kind := EntityKind(obj.Type)
+			if !IsEntityKind(kind) {
+				return nil, fmt.Errorf("object layer %q: object %q has unknown type %q", layer.Name, obj.Name, obj.Type)
+			}
+
+			spawn := EntitySpawn{
+				Kind:       kind,
+				X:          obj.X * scaleX,
+				Y:          obj.Y * scaleY,
+				Width:      obj.Width * scaleX,
+				Height:     obj.Height * scaleY,
+				Name:       obj.Name,
+				Properties: obj.Properties,
+			}
+			// points have no size, give them one tile
+			if spawn.Width == 0 {
+				spawn.Width = LevelTileWidth
+			}
+			if spawn.Height == 0 {
+				spawn.Height = LevelTileHeight
+			}
+			level.Spawns = append(level.Spawns, spawn)
+		}
+	}
+	return level, nil
+}
+
+// decodeTileData turns the data of a tile layer into gids
+func decodeTileData(encoding, compression, data string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		fields := strings.FieldsFunc(data, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+		})
+		gids := make([]uint32, len(fields))
+		for i, f := range fields {
+			v, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("csv tile %d: %w", i, err)
+			}
+			gids[i] = uint32(v)
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, fmt.Errorf("base64 tile data: %w", err)
+		}
+
+		var r io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, fmt.Errorf("zlib tile data: %w", err)
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, fmt.Errorf("gzip tile data: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported tile compression %q", compression)
+		}
+		if raw, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("%s tile data: %w", compression, err)
+		}
+		if len(raw)%4 != 0 {
+			return nil, errors.New("tile data is not a whole number of gids")
+		}
+
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	default:
+		return nil, fmt.Errorf("unsupported tile encoding %q", encoding)
+	}
+}
+
+// ---------------- JSON (.tmj) ----------------
+
+type tmjMap struct {
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	TileWidth   int          `json:"tilewidth"`
+	TileHeight  int          `json:"tileheight"`
+	Infinite    bool         `json:"infinite"`
+	Orientation string       `json:"orientation"`
+	Layers      []tmjLayer   `json:"layers"`
+	Tilesets    []tmjTileset `json:"tilesets"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []tmjObject     `json:"objects"`
+	Layers      []tmjLayer      `json:"layers"` // group layers
+	Properties  []tmjProperty   `json:"properties"`
+}
+
+type tmjObject struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	GID        uint32        `json:"gid"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjTileset struct {
+	FirstGID   uint32        `json:"firstgid"`
+	TileCount  uint32        `json:"tilecount"`
+	Source     string        `json:"source"`
+	Tiles      []tmjTile     `json:"tiles"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjTile struct {
+	ID         uint32        `json:"id"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjProperty struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+func tmjProperties(props []tmjProperty) map[string]string {
+	out := make(map[string]string, len(props))
+	for _, p := range props {
+		out[p.Name] = fmt.Sprint(p.Value)
+	}
+	return out
+}
+
+func readTMJ(path string) (*tiledMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw tmjMap
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Infinite {
+		return nil, errors.New("infinite maps are not supported")
+	}
+	if raw.Orientation != "" && raw.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%s maps are not supported", raw.Orientation)
+	}
+
+	m := &tiledMap{
+		Width:      raw.Width,
+		Height:     raw.Height,
+		TileWidth:  raw.TileWidth,
+		TileHeight: raw.TileHeight,
+	}
+
+	for _, ts := range raw.Tilesets {
+		tileset, err := tmjTilesetFor(ts, filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+
+	var addLayers func(layers []tmjLayer) error
+	addLayers = func(layers []tmjLayer) error {
+		for _, l := range layers {
+			switch l.Type {
+			case "tilelayer":
+				gids, err := tmjLayerData(l)
+				if err != nil {
+					return fmt.Errorf("layer %q: %w", l.Name, err)
+				}
+				m.TileLayers = append(m.TileLayers, tiledTileLayer{Name: l.Name, GIDs: gids, Properties: tmjProperties(l.Properties)})
+			case "objectgroup":
+				layer := tiledObjectLayer{Name: l.Name, Properties: tmjProperties(l.Properties)}
+				for _, o := range l.Objects {
+					layer.Objects = append(layer.Objects, tmjObjectToTiled(o))
+				}
+				m.ObjectLayers = append(m.ObjectLayers, layer)
+			case "group":
+				if err := addLayers(l.Layers); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := addLayers(raw.Layers); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func tmjLayerData(l tmjLayer) ([]uint32, error) {
+	if l.Encoding == "base64" {
+		var s string
+		if err := json.Unmarshal(l.Data, &s); err != nil {
+			return nil, err
+		}
+		return decodeTileData("base64", l.Compression, s)
+	}
+	var gids []uint32
+	if err := json.Unmarshal(l.Data, &gids); err != nil {
+		return nil, err
+	}
+	return gids, nil
+}
+
+func tmjObjectToTiled(o tmjObject) tiledObject {
+	obj := tiledObject{
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		Properties: tmjProperties(o.Properties),
+	}
+	if obj.Type == "" {
+		obj.Type = o.Class
+	}
+	// tile objects are anchored at their bottom left corner
+	if o.GID != 0 {
+		obj.Y -= o.Height
+	}
+	return obj
+}
+
+func tmjTilesetFor(ts tmjTileset, dir string) (tiledTileset, error) {
+	if ts.Source != "" {
+		external, err := readExternalTileset(filepath.Join(dir, ts.Source))
+		if err != nil {
+			return tiledTileset{}, err
+		}
+		external.FirstGID = ts.FirstGID
+		return external, nil
+	}
+
+	tileset := tiledTileset{
+		FirstGID:   ts.FirstGID,
+		TileCount:  ts.TileCount,
+		Tiles:      make(map[uint32]map[string]string, len(ts.Tiles)),
+		Properties: tmjProperties(ts.Properties),
+	}
+	for _, t := range ts.Tiles {
+		tileset.Tiles[t.ID] = tmjProperties(t.Properties)
+	}
+	return tileset, nil
+}
+
+// readExternalTileset reads a .tsj or .tsx tileset file
+func readExternalTileset(path string) (tiledTileset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return tiledTileset{}, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".tsx") {
+		var raw tmxTileset
+		if err := xml.Unmarshal(data, &raw); err != nil {
+			return tiledTileset{}, fmt.Errorf("tileset %s: %w", path, err)
+		}
+		return raw.toTiled(), nil
+	}
+
+	var raw tmjTileset
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return tiledTileset{}, fmt.Errorf("tileset %s: %w", path, err)
+	}
+	return tmjTilesetFor(raw, filepath.Dir(path))
+}
+
+// ---------------- XML (.tmx) ----------------
+
+type tmxMap struct {
+	XMLName      xml.Name         `xml:"map"`
+	Width        int              `xml:"width,attr"`
+	Height       int              `xml:"height,attr"`
+	TileWidth    int              `xml:"tilewidth,attr"`
+	TileHeight   int              `xml:"tileheight,attr"`
+	Infinite     int              `xml:"infinite,attr"`
+	Orientation  string           `xml:"orientation,attr"`
+	Tilesets     []tmxTileset     `xml:"tileset"`
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+	Groups       []tmxGroup       `xml:"group"`
+}
+
+type tmxGroup struct {
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+	Groups       []tmxGroup       `xml:"group"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32        `xml:"firstgid,attr"`
+	TileCount  uint32        `xml:"tilecount,attr"`
+	Source     string        `xml:"source,attr"`
+	Tiles      []tmxTile     `xml:"tile"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxLayer struct {
+	Name       string        `xml:"name,attr"`
+	Data       tmxData       `xml:"data"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+type tmxObjectGroup struct {
+	Name       string        `xml:"name,attr"`
+	Objects    []tmxObject   `xml:"object"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxObject struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxProperties struct {
+	Property []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+		Text  string `xml:",chardata"` // multi-line strings are stored as text
+	} `xml:"property"`
+}
+
+func (p tmxProperties) toMap() map[string]string {
+	out := make(map[string]string, len(p.Property))
+	for _, prop := range p.Property {
+		if prop.Value != "" {
+			out[prop.Name] = prop.Value
+		} else {
+			out[prop.Name] = prop.Text
+		}
+	}
+	return out
+}
+
+func (ts tmxTileset) toTiled() tiledTileset {
+	tileset := tiledTileset{
+		FirstGID:   ts.FirstGID,
+		TileCount:  ts.TileCount,
+		Tiles:      make(map[uint32]map[string]string, len(ts.Tiles)),
+		Properties: ts.Properties.toMap(),
+	}
+	for _, t := range ts.Tiles {
+		tileset.Tiles[t.ID] = t.Properties.toMap()
+	}
+	return tileset
+}
+
+func (o tmxObject) toTiled() tiledObject {
+	obj := tiledObject{
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		Properties: o.Properties.toMap(),
+	}
+	if obj.Type == "" {
+		obj.Type = o.Class
+	}
+	// tile objects are anchored at their bottom left corner
+	if o.GID != 0 {
+		obj.Y -= o.Height
+	}
+	return obj
+}
+
+func readTMX(path string) (*tiledMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw tmxMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Infinite != 0 {
+		return nil, errors.New("infinite maps are not supported")
+	}
+	if raw.Orientation != "" && raw.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%s maps are not supported", raw.Orientation)
+	}
+
+	m := &tiledMap{
+		Width:      raw.Width,
+		Height:     raw.Height,
+		TileWidth:  raw.TileWidth,
+		TileHeight: raw.TileHeight,
+	}
+
+	for _, ts := range raw.Tilesets {
+		if ts.Source != "" {
+			external, err := readExternalTileset(filepath.Join(filepath.Dir(path), ts.Source))
+			if err != nil {
+				return nil, err
+			}
+			external.FirstGID = ts.FirstGID
+			m.Tilesets = append(m.Tilesets, external)
+			continue
+		}
+		m.Tilesets = append(m.Tilesets, ts.toTiled())
+	}
+
+	var addGroup func(layers []tmxLayer, groups []tmxObjectGroup, nested []tmxGroup) error
+	addGroup = func(layers []tmxLayer, groups []tmxObjectGroup, nested []tmxGroup) error {
+		for _, l := range layers {
+			var gids []uint32
+			if l.Data.Encoding == "" {
+				// plain XML, one <tile> element per cell
+				for _, t := range l.Data.Tiles {
+					gids = append(gids, t.GID)
+				}
+			} else {
+				var err error
+				if gids, err = decodeTileData(l.Data.Encoding, l.Data.Compression, l.Data.Text); err != nil {
+					return fmt.Errorf("layer %q: %w", l.Name, err)
+				}
+			}
+			m.TileLayers = append(m.TileLayers, tiledTileLayer{Name: l.Name, GIDs: gids, Properties: l.Properties.toMap()})
+		}
+		for _, g := range groups {
+			layer := tiledObjectLayer{Name: g.Name, Properties: g.Properties.toMap()}
+			for _, o := range g.Objects {
+				layer.Objects = append(layer.Objects, o.toTiled())
+			}
+			m.ObjectLayers = append(m.ObjectLayers, layer)
+		}
+		for _, g := range nested {
+			if err := addGroup(g.Layers, g.ObjectGroups, g.Groups); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := addGroup(raw.Layers, raw.ObjectGroups, raw.Groups); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
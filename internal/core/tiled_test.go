@@ -0,0 +1,163 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// the fixture maps under testdata/tiled are one 4x3 level saved every way
+// the loader reads: ground, a one-way ledge and a rock, a spawn point and a
+// tile object enemy.
+//
+//	R . . .    R: stone, from the tileset property
+//	. . W .    W: wood one-way, its material from the layer property
+//	G G G G    G: grass, from the tile property
+func TestTiledLoader(t *testing.T) {
+	for _, name := range []string{"csv.tmj", "gzip.tmj", "zlib.tmx"} {
+		t.Run(name, func(t *testing.T) {
+			level, err := LoadLevelFile(filepath.Join("testdata", "tiled", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if level.Width != 4*LevelTileWidth || level.Height != 3*LevelTileHeight {
+				t.Fatalf("size = %vx%v, want %vx%v", level.Width, level.Height, 4*LevelTileWidth, 3*LevelTileHeight)
+			}
+
+			type cell struct {
+				x, y  int
+				tile  TileType
+				shape PlatformShape
+			}
+			// layer by layer, the rock is on the second one
+			want := []cell{
+				{2, 1, Wood, ShapeOneWay},
+				{0, 2, Grass, ShapeSolid}, {1, 2, Grass, ShapeSolid}, {2, 2, Grass, ShapeSolid}, {3, 2, Grass, ShapeSolid},
+				{0, 0, Rock, ShapeSolid},
+			}
+			var got []cell
+			for _, p := range level.Platforms {
+				got = append(got, cell{int(p.X) / LevelTileWidth, int(p.Y) / LevelTileHeight, p.TileInfo.TileType, p.Shape})
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("platforms = %v, want %v", got, want)
+			}
+
+			if len(level.Spawns) != 2 {
+				t.Fatalf("%d spawns, want 2, the untyped note left out", len(level.Spawns))
+			}
+			start := level.Spawns[0]
+			if start.Kind != EntitySpawnPoint || start.Name != "start" || start.X != LevelTileWidth || start.Y != LevelTileHeight ||
+				start.Width != LevelTileWidth || start.Height != LevelTileHeight {
+				t.Fatalf("point spawn = %+v, want a one tile spawn at tile (1, 1)", start)
+			}
+			// a tile object sits on its y, the bottom of the tile, so it spans tile row 1
+			guard := level.Spawns[1]
+			if guard.Kind != EntityEnemyBasic || guard.X != 2*LevelTileWidth || guard.Y != LevelTileHeight || guard.Height != LevelTileHeight {
+				t.Fatalf("tile object spawn = %+v, want an enemy at tile (2, 1)", guard)
+			}
+			if guard.Properties[ProjectileProperty] != "arrow" {
+				t.Fatalf("tile object properties = %v", guard.Properties)
+			}
+		})
+	}
+}
+
+func TestTiledLoaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"unknown_encoding.tmx", `unsupported tile encoding "hex"`},
+		{"missing_tileset.tmj", "nowhere.tsj"},
+		{"bad_gid.tmj", "tile 9 is in no tileset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadLevelFile(filepath.Join("testdata", "tiled", tt.name))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeTileData(t *testing.T) {
+	gids := []uint32{0, 1, 2, 0x80000003} // the last one flipped
+	var raw bytes.Buffer
+	for _, g := range gids {
+		binary.Write(&raw, binary.LittleEndian, g)
+	}
+	var zl, gz bytes.Buffer
+	zw := zlib.NewWriter(&zl)
+	zw.Write(raw.Bytes())
+	zw.Close()
+	gw := gzip.NewWriter(&gz)
+	gw.Write(raw.Bytes())
+	gw.Close()
+	b64 := base64.StdEncoding.EncodeToString
+
+	tests := []struct {
+		name, encoding, compression, data string
+		wantErr                           string
+	}{
+		{"csv", "csv", "", "0,1,\n2,2147483651\n", ""},
+		{"base64", "base64", "", b64(raw.Bytes()), ""},
+		{"zlib", "base64", "zlib", "\n  " + b64(zl.Bytes()) + "\n", ""},
+		{"gzip", "base64", "gzip", b64(gz.Bytes()), ""},
+		{"unknown encoding", "hex", "", "00", "unsupported tile encoding"},
+		{"unknown compression", "base64", "zstd", b64(raw.Bytes()), "unsupported tile compression"},
+		{"bad csv", "csv", "", "1,x", "csv tile 1"},
+		{"bad base64", "base64", "", "!!!", "base64 tile data"},
+		{"not zlib", "base64", "zlib", b64(raw.Bytes()), "zlib tile data"},
+		{"partial gid", "base64", "", b64([]byte{1, 0, 0}), "not a whole number of gids"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTileData(tt.encoding, tt.compression, tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, gids) {
+				t.Fatalf("gids = %v, want %v", got, gids)
+			}
+		})
+	}
+}
+
+func TestLoaderFor(t *testing.T) {
+	tests := []struct {
+		path  string
+		tiled bool
+		ok    bool
+	}{
+		{"level.png", false, true},
+		{"level.PNG", false, true},
+		{"level.tmx", true, true},
+		{"level.tmj", true, true},
+		{"level.json", false, false},
+		{"level.palette.json", false, false},
+		{"level", false, false},
+	}
+	for _, tt := range tests {
+		loader, err := LoaderFor(tt.path)
+		if (err == nil) != tt.ok {
+			t.Fatalf("LoaderFor(%q) err = %v, want ok %v", tt.path, err, tt.ok)
+		}
+		if _, isTiled := loader.(TiledLoader); tt.ok && isTiled != tt.tiled {
+			t.Fatalf("LoaderFor(%q) = %T", tt.path, loader)
+		}
+	}
+}
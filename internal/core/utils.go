@@ -1,16 +1,24 @@
 package core
 
 import (
-	"log"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"fmt"
+	"image"
+	_ "image/png" // register the PNG decoder for LoadLevelImage
+	"os"
 )
 
-func LoadImage(path string) *ebiten.Image {
-	img, _, err := ebitenutil.NewImageFromFile(path)
+// LoadLevelImage decodes a level map into a plain image.Image, without ebiten,
+// so levels can be loaded on machines with no display or GPU
+func LoadLevelImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("decode level %s: %w", path, err)
 	}
-	return img
+	return img, nil
 }
@@ -0,0 +1,72 @@
+package core
+
+import "math"
+
+// ------------------------ wall constants ------------------------
+const (
+	WallSlideSpeed = 150 // fall speed cap while sliding down a wall
+	WallJumpSpeedX = 450 // push away from the wall
+	WallJumpForce  = 700
+	WallJumpLockMs = 150 // horizontal input is ignored this long after a wall jump
+)
+
+// ---------------- wall slide / wall jump ----------------
+type WallMove struct {
+	SlideSpeed float64
+	JumpSpeedX float64
+	JumpForce  float64
+	LockMs     float64
+
+	LockLeftMs float64 // remaining ms of the post wall jump input lock
+}
+
+func DefaultWallMove() WallMove {
+	return WallMove{
+		SlideSpeed: WallSlideSpeed,
+		JumpSpeedX: WallJumpSpeedX,
+		JumpForce:  WallJumpForce,
+		LockMs:     WallJumpLockMs,
+	}
+}
+
+// wallJump kicks the player off the wall it is hugging, if jump was pressed.
+// WallDir is still the result of the previous step here.
+func (player *PlayerRuntime) wallJump(inputState *InputState, canJump bool) bool {
+	pressed := inputState.JumpJustPressed || player.Physics.JumpBufferLeft > 0
+	if !canJump || !pressed || player.OnGround || player.WallDir == 0 {
+		return false
+	}
+
+	w := &player.Wall
+	player.Physics.VelX = -float64(player.WallDir) * w.JumpSpeedX
+	player.Physics.VelY = -w.JumpForce
+	player.Physics.JumpBufferLeft = 0
+	player.FlipX = player.WallDir > 0 // face away from the wall
+	w.LockLeftMs = w.LockMs
+	player.State.SetPlayerState(int(PlayerStateWallJump))
+	return true
+}
+
+// updateWallSlide starts and stops sliding once the step has moved the player.
+// Sliding needs the player in the air, falling, and pushing into the wall.
+func (player *PlayerRuntime) updateWallSlide(inputState *InputState, canSlide bool) {
+	pushing := player.WallDir != 0 && int(inputState.Direction.LeftRight) == player.WallDir
+	sliding := canSlide && pushing && !player.OnGround && player.Physics.VelY > 0
+
+	switch {
+	case sliding:
+		player.State.SetPlayerState(int(PlayerStateWallSlide))
+		player.FlipX = player.WallDir < 0 // face the wall
+	case player.State.IsWallSliding() && player.OnGround:
+		player.State.SetPlayerState(int(PlayerStateIdle))
+	case player.State.IsWallSliding():
+		player.State.SetPlayerState(int(PlayerStateFalling))
+	}
+}
+
+// capWallSlide slows the fall while sliding
+func (player *PlayerRuntime) capWallSlide() {
+	if player.State.IsWallSliding() {
+		player.Physics.VelY = math.Min(player.Physics.VelY, player.Wall.SlideSpeed)
+	}
+}
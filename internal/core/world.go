@@ -3,8 +3,6 @@ package core
 import (
 	"fmt"
 	"image"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // eg:= grass ,ice ,sand etc.
@@ -14,7 +12,7 @@ type TileLength int
 
 const (
 	// tileset constants
-	Tileset                     = "../assets/LevelTile.png"
+	Tileset                     = "LevelTile.png" // under assets.Dir
 	Grass            TileType   = "grass"
 	Rock             TileType   = "stone"
 	Ice              TileType   = "ice"
@@ -23,7 +21,6 @@ const (
 	Metal            TileType   = "metal"
 	Water            TileType   = "water"
 	Wood             TileType   = "wood"
-	EnemyBasic       TileType   = "enemyBasic"
 	Empty            TileType   = "empty"
 	TopTileLen       TileLength = 11
 	BottomTileLen    TileLength = 4
@@ -36,16 +33,27 @@ const (
 	TopTileXStart               = 0
 	BotTileXStart               = TotTopTilesWidth
 
-	// level 1 constants
-	Level_1      = "../assets/Level_1.png"
-	Background_1 = "../assets/LBackground_3.png"
-
 	// ---------------- level constants ----------------
 	LevelTileWidth      = 60      // before it was 40
 	LevelTileHeight     = 60      // before it was 40
 	TopTileVisualOffset = 26.5625 // for water ,grass ,sand
-	Level_1_Width       = 6000
-	Level_1_Height      = 2040
+	DefaultSpawnX       = 100     // player start when a level has no spawn point
+	DefaultSpawnY       = 100
+	DefaultCheckpointID = "default" // the level spawn point, active until the player touches a checkpoint
+)
+
+// eg:= enemy spawns, checkpoints, level exits
+type EntityKind string
+
+const (
+	EntityEnemyBasic  EntityKind = "enemyBasic"
+	EntityEnemyRanged EntityKind = "enemyRanged"
+	EntitySpawnPoint  EntityKind = "spawn"
+	EntityCheckpoint  EntityKind = "checkpoint"
+	EntityExit        EntityKind = "exit"
+
+	EntityMovingPlatform    EntityKind = "movingPlatform"
+	EntityCrumblingPlatform EntityKind = "crumblingPlatform"
 )
 
 type Tile struct {
@@ -56,16 +64,74 @@ type Tile struct {
 
 // ---------------- platform ----------------
 type Platform struct {
-	X, Y, Width, Height float64 // these are the world coordinates
-	TileInfo            Tile    // this is the tile information
-	DrawOffsetY         float64 // this is the draw offset for the tile
+	X, Y, Width, Height float64       // these are the world coordinates
+	TileInfo            Tile          // this is the tile information
+	DrawOffsetY         float64       // this is the draw offset for the tile
+	Shape               PlatformShape // full block, one-way or slope
+
+	Motion  *PlatformMotion // set on moving platforms
+	Crumble *Crumble        // set on crumbling platforms
+}
+
+// ---------------- entity spawn ----------------
+// EntitySpawn is a non-solid marker placed in a level, eg: where an enemy starts
+type EntitySpawn struct {
+	Kind                EntityKind
+	X, Y, Width, Height float64 // world coordinates of the marker
+	Name                string
+	Properties          map[string]string
+}
+
+// ---------------- level data ----------------
+// LevelData is everything a level loader produces
+type LevelData struct {
+	Platforms     []Platform
+	Spawns        []EntitySpawn
+	Width, Height float64 // level size in world units
+}
+
+// FirstSpawn returns the first spawn of the given kind
+func (l *LevelData) FirstSpawn(kind EntityKind) (EntitySpawn, bool) {
+	for _, spawn := range l.Spawns {
+		if spawn.Kind == kind {
+			return spawn, true
+		}
+	}
+	return EntitySpawn{}, false
 }
 
+// Tiles maps a tile type to its top and bottom tile in the tileset. It is
+// filled at package init and only read after that, so loaders on any
+// goroutine can share it.
 var Tiles map[TileType][2]Tile
 
-// initialize the world at the start of the program
-func WorldInit() {
-	// Initialize the map
+func init() {
+	initTiles()
+}
+
+// IsTileType reports whether t is a tile the tileset can draw
+func IsTileType(t TileType) bool {
+	switch t {
+	case Grass, Rock, Ice, Sand, Larva, Metal, Water, Wood:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsEntityKind reports whether k is an entity levels may place
+func IsEntityKind(k EntityKind) bool {
+	switch k {
+	case EntityEnemyBasic, EntityEnemyRanged, EntitySpawnPoint, EntityCheckpoint, EntityExit,
+		EntityMovingPlatform, EntityCrumblingPlatform:
+		return true
+	default:
+		return false
+	}
+}
+
+// initTiles fills Tiles
+func initTiles() {
 	Tiles = make(map[TileType][2]Tile)
 	Tiles[Water] = [2]Tile{
 		{X: TopTileXStart, Y: PixelTileHeight * 0, TileType: Water, TileLvl: TopTile},
@@ -99,12 +165,6 @@ func WorldInit() {
 		{X: TopTileXStart, Y: PixelTileHeight * 7, TileType: Larva, TileLvl: TopTile},
 		{X: BotTileXStart, Y: PixelTileHeight * 7, TileType: Larva, TileLvl: BottomTile},
 	}
-	Tiles[EnemyBasic] = [2]Tile{
-		// this is the enemy basic tile which is used to spawn enemies
-		// we only need the position of this platform to spawn enemies
-		{X: 0, Y: 0, TileType: EnemyBasic, TileLvl: TopTile},
-		{X: 0, Y: 0, TileType: EnemyBasic, TileLvl: BottomTile},
-	}
 }
 
 // GetBounds returns the bounding box of the platform
@@ -117,37 +177,10 @@ func (p *Platform) GetBounds() AABB {
 	}
 }
 
-func getTileType(r, g, b uint32) TileType {
-	// larva color code -> 255 ,0 ,0
-	// grass color code -> 0 ,255 ,0
-	// water color code -> 0 ,0 ,255
-	// stone color code -> 255 ,255 ,0
-	switch {
-	case r == 255 && g == 0 && b == 0:
-		return Larva
-	case r == 0 && g == 255 && b == 0:
-		return Grass
-	case r == 0 && g == 0 && b == 255:
-		return Water
-	case r == 255 && g == 255 && b == 0:
-		return Rock
-	case r == 0 && g == 0 && b == 0:
-		return EnemyBasic
-	default:
-		return Empty
-	}
-}
-
-func getColor(x, y int, levelData *ebiten.Image) (uint32, uint32, uint32) {
-	color := levelData.At(x, y)
-	r, g, b, _ := color.RGBA()
-	r >>= 8
-	g >>= 8
-	b >>= 8
-	return r, g, b
-}
-
-func getTileInfo(x, y int, tileType TileType, levelData *ebiten.Image, prevPlat Platform) Platform {
+// getTileInfo builds the platform for the cell (x, y). solidAbove tells whether
+// the cell above is a tile too, in which case the bottom variant is used.
+// Slopes always use the bottom variant, the top one has no ground up its edge.
+func getTileInfo(x, y int, tileType TileType, shape PlatformShape, solidAbove bool, prevPlat Platform) Platform {
 	// Create the platform with basic world coordinates
 	plat := Platform{
 		X:      float64(x * LevelTileWidth),
@@ -159,13 +192,12 @@ func getTileInfo(x, y int, tileType TileType, levelData *ebiten.Image, prevPlat
 			TileLvl:  TopTile, // Default to Top
 		},
 		DrawOffsetY: 0,
+		Shape:       shape,
 	}
 
 	// check for bottom tile
-	if y > 0 {
-		if getTileType(getColor(x, y-1, levelData)) != Empty {
-			plat.TileInfo.TileLvl = BottomTile
-		}
+	if solidAbove || shape.IsSlope() {
+		plat.TileInfo.TileLvl = BottomTile
 	}
 
 	if plat.TileInfo.TileLvl == TopTile {
@@ -194,9 +226,6 @@ func getTileInfo(x, y int, tileType TileType, levelData *ebiten.Image, prevPlat
 		case Larva:
 			plat.TileInfo.X = Tiles[Larva][0].X
 			plat.TileInfo.Y = Tiles[Larva][0].Y
-		case EnemyBasic:
-			plat.TileInfo.X = Tiles[EnemyBasic][0].X
-			plat.TileInfo.Y = Tiles[EnemyBasic][0].Y
 		default:
 			plat.TileInfo.X = 0
 			plat.TileInfo.Y = 0
@@ -233,9 +262,6 @@ func getTileInfo(x, y int, tileType TileType, levelData *ebiten.Image, prevPlat
 		case Larva:
 			plat.TileInfo.X = Tiles[Larva][1].X
 			plat.TileInfo.Y = Tiles[Larva][1].Y
-		case EnemyBasic:
-			plat.TileInfo.X = Tiles[EnemyBasic][1].X
-			plat.TileInfo.Y = Tiles[EnemyBasic][1].Y
 		default:
 			plat.TileInfo.X = 0
 			plat.TileInfo.Y = 0
@@ -258,98 +284,52 @@ func getTileInfo(x, y int, tileType TileType, levelData *ebiten.Image, prevPlat
 	return plat
 }
 
-// LoadLevel loads the level from the image
-func (p *PlayerRuntime) LoadLevel(levelData *ebiten.Image) []Platform {
-	level := []Platform{}
+// LoadLevel loads the level from the image, one pixel per tile, using palette
+// to tell tiles and entities apart. Any image.Image works, so a decoded PNG can
+// be used without a window.
+func LoadLevel(levelData image.Image, palette *Palette) (*LevelData, error) {
+	bounds := levelData.Bounds()
+	level := &LevelData{
+		Width:  float64(bounds.Dx() * LevelTileWidth),
+		Height: float64(bounds.Dy() * LevelTileHeight),
+	}
 
 	var prevPlat Platform = Platform{TileInfo: Tile{TileType: Empty}}
 
-	fmt.Println(levelData.Bounds())
-
-	for y := 0; y < levelData.Bounds().Dy(); y++ {
+	for y := 0; y < bounds.Dy(); y++ {
 		prevPlat.TileInfo.TileType = Empty
 
-		for x := 0; x < levelData.Bounds().Dx(); x++ {
-			tileType := getTileType(getColor(x, y, levelData))
+		for x := 0; x < bounds.Dx(); x++ {
+			pixel := levelData.At(bounds.Min.X+x, bounds.Min.Y+y)
+			tileType, entity, err := palette.Lookup(pixel)
+			if err != nil {
+				return nil, fmt.Errorf("level pixel (%d, %d): %w", x, y, err)
+			}
+
+			if entity != "" {
+				level.Spawns = append(level.Spawns, EntitySpawn{
+					Kind:   entity,
+					X:      float64(x * LevelTileWidth),
+					Y:      float64(y * LevelTileHeight),
+					Width:  LevelTileWidth,
+					Height: LevelTileHeight,
+				})
+			}
 
 			if tileType != Empty {
-				level = append(level, getTileInfo(x, y, tileType, levelData, prevPlat))
+				solidAbove := false
+				if y > 0 {
+					above, _, _ := palette.Lookup(levelData.At(bounds.Min.X+x, bounds.Min.Y+y-1))
+					solidAbove = above != Empty
+				}
+				level.Platforms = append(level.Platforms, getTileInfo(x, y, tileType, palette.ShapeAt(pixel), solidAbove, prevPlat))
 			}
 
-			if len(level) > 0 {
-				prevPlat = level[len(level)-1]
+			if len(level.Platforms) > 0 {
+				prevPlat = level.Platforms[len(level.Platforms)-1]
 			}
 			prevPlat.TileInfo.TileType = tileType
 		}
 	}
-	return level
-}
-
-func (player *PlayerRuntime) DrawParallaxBackground(screen *ebiten.Image, background *ebiten.Image, screenWidth, screenHeight float64) {
-	bgW := background.Bounds().Dx()
-	bgH := background.Bounds().Dy()
-
-	parallaxFactor := 0.3
-
-	baseScale := screenHeight / float64(bgH)
-	scale := baseScale * (1 + parallaxFactor)
-
-	scaledW := float64(bgW) * scale
-
-	// Calculate offset based on camera position relative to total level width
-	maxLevelWidth := float64(Level_1_Width)
-	maxCamX := maxLevelWidth - screenWidth
-	if maxCamX < 1 {
-		maxCamX = 1
-	}
-
-	currentCamX := player.Camera.Pos.X
-	if currentCamX < 0 {
-		currentCamX = 0
-	} else if currentCamX > maxCamX {
-		currentCamX = maxCamX
-	}
-
-	// Map camera progress (0-1) to background scroll
-	// We shift the background from 0 to -(scaledW - screenWidth)
-	progression := currentCamX / maxCamX
-	maxBgOffset := scaledW - screenWidth
-	bgOffset := progression * maxBgOffset
-
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(scale, scale)
-	op.GeoM.Translate(-bgOffset, 0)
-	screen.DrawImage(background, op)
-}
-
-func (player *PlayerRuntime) DrawLevel(screen *ebiten.Image, quadtree *DynamicQuadtree, screenWidth, screenHeight float64, tileset *ebiten.Image) {
-
-	// Define the camera viewport
-	viewport := AABB{
-		X:      player.Camera.Pos.X,
-		Y:      player.Camera.Pos.Y,
-		Width:  screenWidth,
-		Height: screenHeight,
-	}
-
-	// Retrieve visible platforms from the Quadtree
-	visibleObjects := quadtree.Retrieve(viewport)
-
-	// Draw visible platforms
-	for _, obj := range visibleObjects {
-		if p, ok := obj.(*Platform); ok {
-			// draw the tile image
-			op := &ebiten.DrawImageOptions{}
-			// Scale the tile image (PixelTileWidth/Height) to fit the platform size (LevelTileWidth/Height)
-			scaleX := float64(LevelTileWidth) / float64(PixelTileWidth)
-			scaleY := float64(LevelTileHeight) / float64(PixelTileHeight)
-			op.GeoM.Scale(scaleX, scaleY)
-
-			// translate is used to position the tile image on the screen
-			op.GeoM.Translate(float64(p.X-player.Camera.Pos.X), float64(p.Y+p.DrawOffsetY-player.Camera.Pos.Y))
-
-			// Draw the sub-image from the tileset using coordinates from TileInfo
-			screen.DrawImage(tileset.SubImage(image.Rect(int(p.TileInfo.X), int(p.TileInfo.Y), int(p.TileInfo.X)+PixelTileWidth, int(p.TileInfo.Y)+PixelTileHeight)).(*ebiten.Image), op)
-		}
-	}
+	return level, nil
 }
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+
+	"player/internal/core"
+)
+
+// RespawnDelay is how long the dead animation plays, in seconds, before the game is over
+const RespawnDelay = 1.5
+
+// Checkpoint is a respawn point. Every checkpoint owns the strip of the level
+// from its own X up to the next checkpoint, enemies that spawned in that
+// strip come back when the player respawns there.
+type Checkpoint struct {
+	ID     string
+	Area   core.AABB     // touching this activates the checkpoint
+	Spawn  core.Position // where the player respawns, feet on the bottom of Area
+	Region core.AABB     // enemies that spawned in here reset on respawn
+	Active bool
+}
+
+// buildCheckpoints collects the checkpoints of a level. The level spawn point
+// is always there as core.DefaultCheckpointID, active from the start.
+func (w *World) buildCheckpoints(level *core.LevelData) {
+	player := w.Player.GetBounds()
+	w.Checkpoints = []Checkpoint{{
+		ID:     core.DefaultCheckpointID,
+		Area:   core.AABB{X: w.Spawn.X, Y: w.Spawn.Y, Width: player.Width, Height: player.Height},
+		Spawn:  w.Spawn,
+		Active: true,
+	}}
+
+	for _, spawn := range level.Spawns {
+		if spawn.Kind != core.EntityCheckpoint {
+			continue
+		}
+		id := spawn.Name
+		if id == "" {
+			id = fmt.Sprintf("checkpoint_%d", len(w.Checkpoints))
+		}
+		w.Checkpoints = append(w.Checkpoints, Checkpoint{
+			ID:    id,
+			Area:  core.AABB{X: spawn.X, Y: spawn.Y, Width: spawn.Width, Height: spawn.Height},
+			Spawn: core.Position{X: spawn.X, Y: spawn.Y + spawn.Height - player.Height},
+		})
+	}
+
+	// split the level into strips, left to right
+	sort.SliceStable(w.Checkpoints, func(i, j int) bool {
+		return w.Checkpoints[i].Spawn.X < w.Checkpoints[j].Spawn.X
+	})
+	for i := range w.Checkpoints {
+		left, right := 0.0, w.Width
+		if i > 0 {
+			left = w.Checkpoints[i].Spawn.X
+		}
+		if i+1 < len(w.Checkpoints) {
+			right = w.Checkpoints[i+1].Spawn.X
+		}
+		w.Checkpoints[i].Region = core.AABB{X: left, Y: 0, Width: right - left, Height: w.Height}
+	}
+}
+
+// Checkpoint looks a checkpoint of the current level up by ID
+func (w *World) Checkpoint(id string) (*Checkpoint, bool) {
+	for i := range w.Checkpoints {
+		if w.Checkpoints[i].ID == id {
+			return &w.Checkpoints[i], true
+		}
+	}
+	return nil, false
+}
+
+// checkCheckpoints activates the checkpoints the player touches, the last
+// one touched is where the player respawns
+func (w *World) checkCheckpoints() {
+	bounds := w.Player.GetBounds()
+	for i := range w.Checkpoints {
+		cp := &w.Checkpoints[i]
+		if cp.ID == w.Player.CheckpointID || !bounds.Intersects(cp.Area) {
+			continue
+		}
+		cp.Active = true
+		w.Player.CheckpointID = cp.ID
+	}
+}
+
+// checkDeath kills the player when out of health or below the level, and
+// times how long it has been dead
+func (w *World) checkDeath(dt float64) {
+	if w.Player.State.IsDead() {
+		w.DeathTimer += dt
+		return
+	}
+	if w.Player.Combat.Health <= 0 || w.Player.Pos.Y > w.Height {
+		w.Player.Die()
+		w.DeathTimer = 0
+	}
+}
+
+// PlayerDead reports whether the player has died
+func (w *World) PlayerDead() bool {
+	return w.Player.State.IsDead()
+}
+
+// GameOver reports whether the player has been dead long enough for the dead
+// animation to finish
+func (w *World) GameOver() bool {
+	return w.PlayerDead() && w.DeathTimer >= RespawnDelay
+}
+
+// Respawn brings the player back at the last activated checkpoint with full
+// health and potions refilled, resets the enemies of that checkpoint's region
+// and puts crumbled platforms back.
+// Step calls it once the game is over and the input asks to respawn.
+func (w *World) Respawn() {
+	cp, ok := w.Checkpoint(w.Player.CheckpointID)
+	if !ok {
+		cp, _ = w.Checkpoint(core.DefaultCheckpointID)
+	}
+	w.Enemies.ResetRegion(cp.Region)
+	w.Projectiles.Clear(w.Quadtree)
+	w.restorePlatforms()
+
+	w.Player.CheckpointID = cp.ID
+	w.Player.Combat.Health = w.Player.Combat.MaxHealth
+	w.Player.Potions.Refill()
+	w.Player.Reset(cp.Spawn)
+	w.DeathTimer = 0
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"testing"
+
+	"player/internal/core"
+)
+
+func TestDefaultCheckpointHasPlayerSize(t *testing.T) {
+	w := newTestWorld(t)
+	cp, ok := w.Checkpoint(core.DefaultCheckpointID)
+	if !ok {
+		t.Fatal("no default checkpoint")
+	}
+	player := w.Player.GetBounds()
+	if cp.Area.Width != player.Width || cp.Area.Height != player.Height {
+		t.Fatalf("default checkpoint is %vx%v, want the player's %vx%v", cp.Area.Width, cp.Area.Height, player.Width, player.Height)
+	}
+	if !cp.Area.Intersects(core.AABB{X: w.Spawn.X, Y: w.Spawn.Y, Width: player.Width, Height: player.Height}) {
+		t.Fatal("a player standing on the spawn point does not touch the default checkpoint")
+	}
+}
+
+// inQuadtree reports whether p is in the world's quadtree
+func inQuadtree(w *World, p *core.Platform) bool {
+	for _, obj := range w.Quadtree.Retrieve(p.GetBounds()) {
+		if obj == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRespawnRestoresCrumbledPlatforms(t *testing.T) {
+	w := newTestWorld(t)
+	newCrumble := func(x float64) *core.Platform {
+		p := &core.Platform{X: x, Y: 300, Width: core.LevelTileWidth, Height: core.LevelTileHeight,
+			TileInfo: core.Tile{TileType: core.Wood}, Crumble: &core.Crumble{DelaySec: 0.5, RespawnSec: 60}}
+		w.Quadtree.Insert(p)
+		w.Dynamic = append(w.Dynamic, p)
+		return p
+	}
+	broken, shaking := newCrumble(300), newCrumble(600)
+
+	broken.Crumble.Trigger()
+	w.updatePlatforms(1)
+	shaking.Crumble.Trigger()
+	if broken.Crumble.State != core.CrumbleBroken || inQuadtree(w, broken) {
+		t.Fatal("the first platform did not crumble away")
+	}
+
+	w.Player.Die()
+	w.Respawn()
+	for _, p := range []*core.Platform{broken, shaking} {
+		if p.Crumble.State != core.CrumbleIntact || p.Crumble.Timer != 0 {
+			t.Fatalf("platform at x %v is %v after respawn, want intact", p.X, p.Crumble.State)
+		}
+		if !inQuadtree(w, p) {
+			t.Fatalf("platform at x %v is not back in the quadtree", p.X)
+		}
+	}
+}
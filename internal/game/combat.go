@@ -0,0 +1,26 @@
+package game
+
+import "player/internal/core"
+
+const (
+	ScorePerKill     = 100 // what defeating an enemy is worth
+	ScorePerComboHit = 10  // a hit is worth this times the combo counter
+)
+
+// checkPlayerHits lands the player's current attack on the enemies it
+// overlaps and credits the damage to their managers
+func (w *World) checkPlayerHits() {
+	w.recordStrikes(w.Player.Strike(w.Quadtree))
+}
+
+// recordStrikes credits hits on enemies to their managers and scores the
+// hits, the combo chains they finish and the kills
+func (w *World) recordStrikes(strikes []core.Strike) {
+	for _, strike := range strikes {
+		w.Enemies.RecordStrike(strike)
+		w.Score += ScorePerComboHit*strike.Combo + strike.Bonus
+		if strike.Killed {
+			w.Score += ScorePerKill
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package game
+
+import "fmt"
+
+// LevelInfo describes one level of the campaign. The level size is not stored
+// here, it comes from the map when the level loads.
+type LevelInfo struct {
+	ID         string
+	MapPath    string // PNG or Tiled map, see core.LoaderFor. Relative to assets.Dir.
+	Background string // parallax background image, relative to assets.Dir
+	Next       string // level the exit leads to, "" ends the campaign
+}
+
+// ExitNextProperty on an exit entity overrides LevelInfo.Next, eg: for secret exits
+const ExitNextProperty = "next"
+
+// Levels is the campaign, in play order
+var Levels = []LevelInfo{
+	{
+		ID:         "level_1",
+		MapPath:    "Level_1.png",
+		Background: "LBackground_3.png",
+		Next:       "level_2",
+	},
+	{
+		ID:         "level_2",
+		MapPath:    "Level_2.png",
+		Background: "LBackground_1.png",
+		Next:       "",
+	},
+}
+
+// FirstLevel returns where a new game starts
+func FirstLevel() LevelInfo {
+	return Levels[0]
+}
+
+// LevelByID looks a level up in the registry
+func LevelByID(id string) (LevelInfo, error) {
+	for _, info := range Levels {
+		if info.ID == id {
+			return info, nil
+		}
+	}
+	return LevelInfo{}, fmt.Errorf("unknown level %q", id)
+}
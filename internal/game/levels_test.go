@@ -0,0 +1,235 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"player/assets"
+	"player/internal/core"
+)
+
+func TestLevelRegistry(t *testing.T) {
+	if FirstLevel().ID != Levels[0].ID {
+		t.Fatalf("FirstLevel() = %q, want %q", FirstLevel().ID, Levels[0].ID)
+	}
+	seen := map[string]bool{}
+	for _, info := range Levels {
+		if seen[info.ID] {
+			t.Fatalf("level %q is registered twice", info.ID)
+		}
+		seen[info.ID] = true
+	}
+	for _, info := range Levels {
+		if info.Next == "" {
+			continue
+		}
+		if _, err := LevelByID(info.Next); err != nil {
+			t.Fatalf("level %q leads to %v", info.ID, err)
+		}
+	}
+	if _, err := LevelByID("no_such_level"); err == nil {
+		t.Fatal("LevelByID found a level that isn't registered")
+	}
+}
+
+// TestRegisteredLevelsLoad loads the real maps, with their palettes, from
+// assets.Dir, which does not depend on where the test runs from
+func TestRegisteredLevelsLoad(t *testing.T) {
+	for _, info := range Levels {
+		t.Run(info.ID, func(t *testing.T) {
+			level, err := core.LoadLevelFile(assets.Path(info.MapPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(level.Platforms) == 0 {
+				t.Fatal("level has no platforms")
+			}
+			if _, err := os.Stat(assets.Path(info.Background)); err != nil {
+				t.Fatalf("background: %v", err)
+			}
+
+			w := newTestWorld(t)
+			if err := w.EnterLevel(info); err != nil {
+				t.Fatal(err)
+			}
+			if w.LevelID != info.ID {
+				t.Fatalf("world is on %q, want %q", w.LevelID, info.ID)
+			}
+		})
+	}
+}
+
+var (
+	testEnemy = color.RGBA{0, 0, 0, 255}
+	testExit  = color.RGBA{255, 128, 0, 255}
+)
+
+// fixturePalette is testPalette plus enemies and exits, in palette file form
+const fixturePalette = `{
+  "empty": ["#FFFFFF"],
+  "tiles": {"#00FF00": "grass"},
+  "entities": {"#0000FF": "spawn", "#000000": "enemyBasic", "#FF8000": "exit"}
+}`
+
+// writeFixtureLevel writes a flat map with the spawn point on the left, an
+// exit right beside it and enemies enemies in the middle, plus its palette
+func writeFixtureLevel(t *testing.T, dir, name string, enemies int) string {
+	t.Helper()
+	const w, h = 20, 8
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, testEmpty)
+		}
+	}
+	for x := 0; x < w; x++ {
+		img.Set(x, h-1, testGrass)
+	}
+	img.Set(2, h-2, testSpawn)
+	img.Set(4, h-2, testExit)
+	for i := 0; i < enemies; i++ {
+		img.Set(10+i, h-2, testEnemy)
+	}
+
+	path := filepath.Join(dir, name+".png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(core.PalettePath(path), []byte(fixturePalette), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// useFixtureLevels swaps the campaign for two small fixture levels for the
+// length of the test: fixture_1, with 3 enemies, leads to fixture_2, which ends it
+func useFixtureLevels(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	saved := Levels
+	Levels = []LevelInfo{
+		{ID: "fixture_1", MapPath: writeFixtureLevel(t, dir, "fixture_1", 3), Next: "fixture_2"},
+		{ID: "fixture_2", MapPath: writeFixtureLevel(t, dir, "fixture_2", 0)},
+	}
+	t.Cleanup(func() { Levels = saved })
+}
+
+// newFixtureWorld is a world with a fresh player on the first fixture level
+func newFixtureWorld(t *testing.T) *World {
+	t.Helper()
+	useFixtureLevels(t)
+	player, err := core.InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWorld(&player, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Close)
+	if err := w.EnterLevel(FirstLevel()); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func enemyCount(w *World) int {
+	n := 0
+	for _, m := range w.Enemies.EnemyManager {
+		n += len(m.Enemies)
+	}
+	return n
+}
+
+// inTree counts what the quadtree holds of the whole level
+func inTree(w *World) int {
+	return len(w.Quadtree.Retrieve(core.AABB{X: 0, Y: 0, Width: w.Width, Height: w.Height}))
+}
+
+func TestAdvanceLevel(t *testing.T) {
+	w := newFixtureWorld(t)
+	if w.LevelID != "fixture_1" || enemyCount(w) != 3 {
+		t.Fatalf("started on %q with %d enemies, want fixture_1 with 3", w.LevelID, enemyCount(w))
+	}
+	if changed, err := w.AdvanceLevel(); changed || err != nil {
+		t.Fatalf("AdvanceLevel() without an exit = %v, %v", changed, err)
+	}
+
+	// walk into the exit beside the spawn point
+	for i := 0; i < 5*core.TickRate && w.LevelID == "fixture_1"; i++ {
+		if err := w.Run(1, holdRight); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w.LevelID != "fixture_2" {
+		t.Fatalf("level %q after walking into the exit, want fixture_2", w.LevelID)
+	}
+	if w.PendingLevel != "" {
+		t.Fatalf("PendingLevel = %q after the level changed", w.PendingLevel)
+	}
+	if enemyCount(w) != 0 {
+		t.Fatalf("%d enemies of fixture_1 came along", enemyCount(w))
+	}
+	if got, want := inTree(w), len(w.Level); got != want {
+		t.Fatalf("quadtree holds %d objects, want the %d platforms of fixture_2", got, want)
+	}
+	if w.Player.Pos != w.Spawn {
+		t.Fatalf("player at %+v, want the spawn point %+v", w.Player.Pos, w.Spawn)
+	}
+
+	// the exit of the last level ends the campaign
+	if err := w.Run(5*core.TickRate, holdRight); err != nil {
+		t.Fatal(err)
+	}
+	if !w.CampaignComplete || w.LevelID != "fixture_2" {
+		t.Fatalf("CampaignComplete = %v on %q, want true on fixture_2", w.CampaignComplete, w.LevelID)
+	}
+}
+
+func TestAdvanceLevelUnknown(t *testing.T) {
+	w := newFixtureWorld(t)
+	w.PendingLevel = "no_such_level"
+	if _, err := w.AdvanceLevel(); err == nil {
+		t.Fatal("AdvanceLevel() into an unregistered level did not fail")
+	}
+}
+
+func TestUnload(t *testing.T) {
+	w := newFixtureWorld(t)
+	if !w.Projectiles.Spawn(w.Quadtree, "arrow", core.TeamPlayer, w.Player.GetBounds(), false) {
+		t.Fatal("could not fire a projectile")
+	}
+	w.PendingLevel = "fixture_2"
+
+	w.Unload()
+	if w.LevelID != "" || w.Level != nil || w.Dynamic != nil || w.Exits != nil || w.Checkpoints != nil || w.PendingLevel != "" {
+		t.Fatalf("level state left after Unload: %q %d platforms %d exits %d checkpoints, pending %q",
+			w.LevelID, len(w.Level), len(w.Exits), len(w.Checkpoints), w.PendingLevel)
+	}
+	if enemyCount(w) != 0 {
+		t.Fatalf("%d enemies left after Unload", enemyCount(w))
+	}
+	if w.Projectiles.Live() != 0 {
+		t.Fatalf("%d projectiles left after Unload", w.Projectiles.Live())
+	}
+	if n := inTree(w); n != 0 {
+		t.Fatalf("quadtree holds %d objects after Unload", n)
+	}
+
+	// and the world loads again after it
+	if err := w.EnterLevel(FirstLevel()); err != nil {
+		t.Fatal(err)
+	}
+	if enemyCount(w) != 3 {
+		t.Fatalf("%d enemies after loading again, want 3", enemyCount(w))
+	}
+}
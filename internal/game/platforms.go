@@ -0,0 +1,28 @@
+package game
+
+// updatePlatforms crumbles the platforms something stood on last step, then
+// moves every moving platform and runs the crumble timers
+func (w *World) updatePlatforms(dt float64) {
+	if p := w.Player.StandingOn(); p != nil && p.Crumble != nil {
+		p.Crumble.Trigger()
+	}
+	for i := range w.Enemies.EnemyManager {
+		enemies := w.Enemies.EnemyManager[i].Enemies
+		for j := range enemies {
+			if p := enemies[j].StandingOn(); p != nil && p.Crumble != nil {
+				p.Crumble.Trigger()
+			}
+		}
+	}
+
+	for _, p := range w.Dynamic {
+		p.Update(w.Quadtree, dt)
+	}
+}
+
+// restorePlatforms puts every crumbled platform back, eg: on respawn
+func (w *World) restorePlatforms() {
+	for _, p := range w.Dynamic {
+		p.Restore(w.Quadtree)
+	}
+}
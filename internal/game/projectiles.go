@@ -0,0 +1,36 @@
+package game
+
+import "player/internal/core"
+
+// fireProjectiles spawns the projectiles the animations of the step asked for.
+// A spawnProjectile event without a name fires the enemy's own projectile.
+func (w *World) fireProjectiles() {
+	for _, ev := range w.Player.FrameEvents {
+		if ev.Kind == core.EventSpawnProjectile {
+			w.Projectiles.Spawn(w.Quadtree, ev.Name, core.TeamPlayer, w.Player.GetBounds(), w.Player.FlipX)
+		}
+	}
+
+	for i := range w.Enemies.EnemyManager {
+		m := &w.Enemies.EnemyManager[i]
+		for j := range m.Enemies {
+			e := &m.Enemies[j]
+			for _, ev := range e.FrameEvents {
+				if ev.Kind != core.EventSpawnProjectile {
+					continue
+				}
+				kind := ev.Name
+				if kind == "" {
+					kind = e.Projectile
+				}
+				w.Projectiles.Spawn(w.Quadtree, kind, core.TeamEnemy, e.GetBounds(), e.FlipX)
+			}
+		}
+	}
+}
+
+// updateProjectiles flies the projectiles and credits their hits like the
+// player's own swings
+func (w *World) updateProjectiles(dt float64) {
+	w.recordStrikes(w.Projectiles.Update(w.Quadtree, w.Player, dt))
+}
@@ -0,0 +1,377 @@
+package game
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+
+	"player/internal/core"
+)
+
+// ------------------------ replay file format ------------------------
+// magic | version | tick rate | level id | final hash | run count | runs...
+// every run is (length, packed input) so a held key costs a few bytes for the whole hold.
+const (
+	replayMagic   = "GGRP"
+	replayVersion = 2
+
+	MaxReplayTicks = 4 * 60 * 60 * core.TickRate // 4 hours, longer files are turned down before they fill the memory
+)
+
+// Recording is the per-tick input stream of one run plus what is needed to
+// reproduce it: the level and the tick rate it was played at
+type Recording struct {
+	LevelID   string
+	TickRate  int
+	Inputs    []core.InputState
+	FinalHash uint64 // World.Hash() after the last input, 0 if unknown
+}
+
+// ---------------- recorder ----------------
+
+// Recorder collects the inputs World.Step is fed, one per tick
+type Recorder struct {
+	rec Recording
+}
+
+// NewRecorder starts a recording for the given world
+func NewRecorder(w *World) *Recorder {
+	return &Recorder{rec: Recording{
+		LevelID:  w.LevelID,
+		TickRate: int(math.Round(1 / w.Clock.Step)),
+	}}
+}
+
+// Record appends the input of one tick
+func (r *Recorder) Record(input core.InputState) {
+	r.rec.Inputs = append(r.rec.Inputs, input)
+}
+
+// Finish stamps the final world hash and returns the recording
+func (r *Recorder) Finish(w *World) *Recording {
+	r.rec.FinalHash = w.Hash()
+	return &r.rec
+}
+
+// ---------------- replayer ----------------
+
+// Replayer hands a recording back one tick at a time
+type Replayer struct {
+	rec    *Recording
+	cursor int
+}
+
+func NewReplayer(rec *Recording) *Replayer {
+	return &Replayer{rec: rec}
+}
+
+// Next writes the input of the next tick into input, false once the recording is over
+func (p *Replayer) Next(input *core.InputState) bool {
+	if p.Done() {
+		*input = core.InputState{}
+		return false
+	}
+	*input = p.rec.Inputs[p.cursor]
+	p.cursor++
+	return true
+}
+
+func (p *Replayer) Done() bool {
+	return p.cursor >= len(p.rec.Inputs)
+}
+
+// Replay steps w through every input of rec and reports whether the end
+// state matches the one that was recorded
+func (w *World) Replay(rec *Recording) error {
+	if rec.TickRate > 0 {
+		w.Clock = core.NewSimClock(rec.TickRate)
+	}
+
+	var input core.InputState
+	player := NewReplayer(rec)
+	for player.Next(&input) {
+		w.Step(&input)
+		if _, err := w.AdvanceLevel(); err != nil {
+			return err
+		}
+	}
+
+	if rec.FinalHash != 0 && w.Hash() != rec.FinalHash {
+		return fmt.Errorf("replay diverged: hash %x after %d ticks, recorded %x", w.Hash(), w.Tick, rec.FinalHash)
+	}
+	return nil
+}
+
+// Hash fingerprints the simulation state so two runs can be compared bit for bit
+func (w *World) Hash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(f float64) {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		h.Write(buf[:])
+	}
+
+	put(w.Player.Pos.X)
+	put(w.Player.Pos.Y)
+	put(w.Player.Physics.VelX)
+	put(w.Player.Physics.VelY)
+	put(float64(w.Player.State.GetPlayerState()))
+	put(w.Player.Combat.Health)
+	put(w.Player.Combat.Power)
+	put(float64(w.Player.Potions.Count))
+	for _, e := range w.Player.Statuses.Active {
+		h.Write([]byte(e.Kind))
+		put(e.TimeLeft)
+		put(float64(e.Stacks))
+	}
+	for _, p := range w.Dynamic {
+		put(p.X)
+		put(p.Y)
+		if p.Crumble != nil {
+			put(float64(p.Crumble.State))
+			put(p.Crumble.Timer)
+		}
+	}
+	for _, m := range w.Enemies.EnemyManager {
+		for i := range m.Enemies {
+			put(m.Enemies[i].Pos.X)
+			put(m.Enemies[i].Pos.Y)
+			put(m.Enemies[i].Health)
+		}
+	}
+	w.Projectiles.Each(func(p *core.Projectile) {
+		put(p.Pos.X)
+		put(p.Pos.Y)
+	})
+	return h.Sum64()
+}
+
+// ---------------- encoding ----------------
+
+// input bits, the direction takes two bits per axis (0 none, 1 negative, 2 positive)
+const (
+	bitJumpJustPressed = 4 + iota
+	bitJumpHeld
+	bitDashJustPressed
+	bitRunJustPressed
+	bitMenu
+	bitSmugFace
+	bitWeakAttack
+	bitStrongAttack
+	bitDefense
+	bitUsePotion
+	bitSpecialAttack1
+	bitSpecialAttack2
+	bitSpecialAttack3
+	bitSpecialAttack4
+	bitConfirm
+	bitRespawn
+
+	inputBits = 1<<(bitRespawn+1) - 1 // every bit a packed input may have set
+)
+
+func packAxis(v int8) uint32 {
+	switch {
+	case v < 0:
+		return 1
+	case v > 0:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func unpackAxis(v uint32) int8 {
+	switch v & 3 {
+	case 1:
+		return -1
+	case 2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func packInput(in core.InputState) uint32 {
+	bits := packAxis(in.Direction.LeftRight) | packAxis(in.Direction.UpDown)<<2
+	set := func(bit int, on bool) {
+		if on {
+			bits |= 1 << bit
+		}
+	}
+	set(bitJumpJustPressed, in.JumpJustPressed)
+	set(bitJumpHeld, in.JumpHeld)
+	set(bitDashJustPressed, in.DashJustPressed)
+	set(bitRunJustPressed, in.RunJustPressed)
+	set(bitMenu, in.Menu)
+	set(bitSmugFace, in.SmugFace)
+	set(bitWeakAttack, in.Skills.WeakAttack)
+	set(bitStrongAttack, in.Skills.StrongAttack)
+	set(bitDefense, in.Skills.Defense)
+	set(bitUsePotion, in.Skills.UsePotion)
+	set(bitSpecialAttack1, in.Skills.SpecialAttack1)
+	set(bitSpecialAttack2, in.Skills.SpecialAttack2)
+	set(bitSpecialAttack3, in.Skills.SpecialAttack3)
+	set(bitSpecialAttack4, in.Skills.SpecialAttack4)
+	set(bitConfirm, in.Confirm)
+	set(bitRespawn, in.Respawn)
+	return bits
+}
+
+// validInput reports whether bits is an input packInput could have written:
+// nothing past the last input bit and no axis set both ways
+func validInput(bits uint64) bool {
+	return bits&^inputBits == 0 && bits&3 != 3 && bits>>2&3 != 3
+}
+
+func unpackInput(bits uint32) core.InputState {
+	has := func(bit int) bool { return bits&(1<<bit) != 0 }
+	return core.InputState{
+		Direction: core.Direction{
+			LeftRight: unpackAxis(bits),
+			UpDown:    unpackAxis(bits >> 2),
+		},
+		JumpJustPressed: has(bitJumpJustPressed),
+		JumpHeld:        has(bitJumpHeld),
+		DashJustPressed: has(bitDashJustPressed),
+		RunJustPressed:  has(bitRunJustPressed),
+		Menu:            has(bitMenu),
+		Confirm:         has(bitConfirm),
+		SmugFace:        has(bitSmugFace),
+		Respawn:         has(bitRespawn),
+		Skills: core.Skills{
+			WeakAttack:     has(bitWeakAttack),
+			StrongAttack:   has(bitStrongAttack),
+			Defense:        has(bitDefense),
+			UsePotion:      has(bitUsePotion),
+			SpecialAttack1: has(bitSpecialAttack1),
+			SpecialAttack2: has(bitSpecialAttack2),
+			SpecialAttack3: has(bitSpecialAttack3),
+			SpecialAttack4: has(bitSpecialAttack4),
+		},
+	}
+}
+
+// WriteTo encodes the recording, run-length compressing the input stream
+func (rec *Recording) WriteTo(w io.Writer) (int64, error) {
+	var out []byte
+	out = append(out, replayMagic...)
+	out = append(out, replayVersion)
+	out = binary.AppendUvarint(out, uint64(rec.TickRate))
+	out = binary.AppendUvarint(out, uint64(len(rec.LevelID)))
+	out = append(out, rec.LevelID...)
+	out = binary.LittleEndian.AppendUint64(out, rec.FinalHash)
+
+	// collapse the stream into runs of identical inputs
+	var runs []core.Pair[uint64, uint32]
+	for _, in := range rec.Inputs {
+		bits := packInput(in)
+		if n := len(runs); n > 0 && runs[n-1].Second == bits {
+			runs[n-1].First++
+			continue
+		}
+		runs = append(runs, core.Pair[uint64, uint32]{First: 1, Second: bits})
+	}
+	out = binary.AppendUvarint(out, uint64(len(runs)))
+	for _, run := range runs {
+		out = binary.AppendUvarint(out, run.First)
+		out = binary.AppendUvarint(out, uint64(run.Second))
+	}
+
+	n, err := w.Write(out)
+	return int64(n), err
+}
+
+// ReadRecording decodes a recording written by WriteTo
+func ReadRecording(r io.Reader) (*Recording, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(replayMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("read replay header: %w", err)
+	}
+	if string(header[:len(replayMagic)]) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	version := header[len(replayMagic)]
+	if version != replayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	rec := &Recording{}
+	tickRate, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("read tick rate: %w", err)
+	}
+	rec.TickRate = int(tickRate)
+
+	idLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("read level id: %w", err)
+	}
+	id := make([]byte, idLen)
+	if _, err := io.ReadFull(br, id); err != nil {
+		return nil, fmt.Errorf("read level id: %w", err)
+	}
+	rec.LevelID = string(id)
+
+	var hash [8]byte
+	if _, err := io.ReadFull(br, hash[:]); err != nil {
+		return nil, fmt.Errorf("read final hash: %w", err)
+	}
+	rec.FinalHash = binary.LittleEndian.Uint64(hash[:])
+
+	runCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("read run count: %w", err)
+	}
+	for i := uint64(0); i < runCount; i++ {
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("read run %d: %w", i, err)
+		}
+		if length > MaxReplayTicks-uint64(len(rec.Inputs)) {
+			return nil, fmt.Errorf("replay is longer than %d ticks", MaxReplayTicks)
+		}
+		bits, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("read run %d: %w", i, err)
+		}
+		if !validInput(bits) {
+			return nil, fmt.Errorf("run %d: undefined input bits %#x", i, bits)
+		}
+		in := unpackInput(uint32(bits))
+		for j := uint64(0); j < length; j++ {
+			rec.Inputs = append(rec.Inputs, in)
+		}
+	}
+	return rec, nil
+}
+
+// SaveRecording writes rec to path
+func SaveRecording(path string, rec *Recording) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := rec.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write replay %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// LoadRecording reads a recording from path
+func LoadRecording(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadRecording(f)
+}
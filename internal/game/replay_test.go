@@ -0,0 +1,117 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"player/internal/core"
+)
+
+// replayFile encodes a replay header followed by runs of (length, bits)
+func replayFile(version byte, runs ...uint64) []byte {
+	out := append([]byte(replayMagic), version)
+	out = binary.AppendUvarint(out, core.TickRate)
+	out = binary.AppendUvarint(out, uint64(len("level_1")))
+	out = append(out, "level_1"...)
+	out = binary.LittleEndian.AppendUint64(out, 0)
+	out = binary.AppendUvarint(out, uint64(len(runs)/2))
+	for _, v := range runs {
+		out = binary.AppendUvarint(out, v)
+	}
+	return out
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	rec := &Recording{LevelID: "level_1", TickRate: core.TickRate, FinalHash: 0xdeadbeef}
+	for i := 0; i < 100; i++ {
+		in := core.InputState{Direction: core.Direction{LeftRight: 1}, JumpHeld: i%10 < 5}
+		in.Respawn = i == 99
+		in.Skills.WeakAttack = i == 50
+		rec.Inputs = append(rec.Inputs, in)
+	}
+
+	var buf bytes.Buffer
+	if _, err := rec.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LevelID != rec.LevelID || got.TickRate != rec.TickRate || got.FinalHash != rec.FinalHash {
+		t.Fatalf("header = %q %d %x, want %q %d %x", got.LevelID, got.TickRate, got.FinalHash, rec.LevelID, rec.TickRate, rec.FinalHash)
+	}
+	if len(got.Inputs) != len(rec.Inputs) {
+		t.Fatalf("%d inputs, want %d", len(got.Inputs), len(rec.Inputs))
+	}
+	for i := range rec.Inputs {
+		if got.Inputs[i] != rec.Inputs[i] {
+			t.Fatalf("input %d = %+v, want %+v", i, got.Inputs[i], rec.Inputs[i])
+		}
+	}
+}
+
+func TestReadRecording(t *testing.T) {
+	right := packInput(core.InputState{Direction: core.Direction{LeftRight: 1}})
+	tests := []struct {
+		name      string
+		data      []byte
+		wantTicks int
+		wantErr   string
+	}{
+		{"runs", replayFile(replayVersion, 3, uint64(right), 2, 0), 5, ""},
+		{"old version", replayFile(replayVersion - 1), 0, "unsupported replay version"},
+		{"unknown version", replayFile(replayVersion + 1), 0, "unsupported replay version"},
+		{"not a replay", []byte("PNG\x89abcdef"), 0, "not a replay file"},
+		{"one huge run", replayFile(replayVersion, MaxReplayTicks+1, 0), 0, "longer than"},
+		{"runs adding up past the cap", replayFile(replayVersion, MaxReplayTicks, 0, 1, uint64(right)), 0, "longer than"},
+		{"undefined bit", replayFile(replayVersion, 1, 1<<(bitRespawn+1)), 0, "undefined input bits"},
+		{"bits past 32", replayFile(replayVersion, 1, 1<<40), 0, "undefined input bits"},
+		{"axis both ways", replayFile(replayVersion, 1, 3), 0, "undefined input bits"},
+		{"truncated", replayFile(replayVersion, 1)[:20], 0, "read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := ReadRecording(bytes.NewReader(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rec.Inputs) != tt.wantTicks {
+				t.Fatalf("%d ticks, want %d", len(rec.Inputs), tt.wantTicks)
+			}
+		})
+	}
+}
+
+func TestHashCoversPlayerAndPlatforms(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(w *World)
+	}{
+		{"health", func(w *World) { w.Player.Combat.Health-- }},
+		{"power", func(w *World) { w.Player.Combat.Power-- }},
+		{"potions", func(w *World) { w.Player.Potions.Count-- }},
+		{"status", func(w *World) { w.Player.Statuses.Apply(core.StatusBurn) }},
+		{"platform position", func(w *World) { w.Dynamic[0].X++ }},
+		{"crumble state", func(w *World) { w.Dynamic[0].Crumble.Trigger() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorld(t)
+			w.Dynamic = append(w.Dynamic, &core.Platform{X: 60, Y: 300, Width: 60, Height: 60, Crumble: &core.Crumble{DelaySec: 1, RespawnSec: 1}})
+			before := w.Hash()
+			tt.change(w)
+			if w.Hash() == before {
+				t.Fatal("hash did not change")
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"player/internal/core"
+)
+
+// ------------------------ save file format ------------------------
+// one JSON file per slot. Every file carries the schema version it was written
+// with, older files are migrated step by step up to SaveVersion on load.
+// The file is written from the *Save types below and never from core types,
+// so renaming a Go field can't change the format.
+const (
+	SaveVersion = 3
+	QuickSlot   = 0 // slot F5 / F9 use
+)
+
+// SaveDir is where the slots are written, the game's folder in the user config
+// directory unless the driver points it somewhere else
+var SaveDir = defaultSaveDir()
+
+func defaultSaveDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "saves" // no home to put it in, next to the binary then
+	}
+	return filepath.Join(dir, "PirateAdventure", "saves")
+}
+
+// SaveFile is everything needed to put a world back where it was saved
+type SaveFile struct {
+	Version int       `json:"version"`
+	Slot    int       `json:"slot"`
+	SavedAt time.Time `json:"savedAt"`
+
+	LevelID           string   `json:"levelId"`
+	ActiveCheckpoints []string `json:"activeCheckpoints"`
+	Score             int      `json:"score"`
+	Tick              uint64   `json:"tick"`
+
+	Player  PlayerSave         `json:"player"`
+	Enemies []EnemyManagerSave `json:"enemies"`
+}
+
+type PlayerSave struct {
+	Pos          PositionSave `json:"pos"`
+	VelX         float64      `json:"velX"`
+	VelY         float64      `json:"velY"`
+	FlipX        bool         `json:"flipX"`
+	Combat       CombatSave   `json:"combat"`
+	Potions      PotionsSave  `json:"potions"`
+	CheckpointID string       `json:"checkpointId"`
+}
+
+type PositionSave struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// CombatSave is the player's stats, the tuning of core.Combat, eg: the power
+// regen rate, comes from the character and is not saved
+type CombatSave struct {
+	Health    float64 `json:"health"`
+	MaxHealth float64 `json:"maxHealth"`
+	Power     float64 `json:"power"`
+	MaxPower  float64 `json:"maxPower"`
+}
+
+type PotionsSave struct {
+	Count int `json:"count"`
+	Max   int `json:"max"`
+}
+
+// EnemyManagerSave holds the statistics of one enemy manager, the enemies
+// themselves respawn from the level
+type EnemyManagerSave struct {
+	ID               string  `json:"id"`
+	TotalKills       int     `json:"totalKills"`
+	TotalDeaths      int     `json:"totalDeaths"`
+	TotalDamageDealt float64 `json:"totalDamageDealt"`
+	TotalDamageTaken float64 `json:"totalDamageTaken"`
+}
+
+// saveMigrations upgrade a decoded save by one version, keyed by the version
+// they upgrade from. When a field changes, bump SaveVersion and add a step here.
+var saveMigrations = map[int]func(save map[string]any) error{
+	// 1 -> 2: the potion inventory, older saves start out with the default one
+	1: func(save map[string]any) error {
+		player, ok := save["player"].(map[string]any)
+		if !ok {
+			return errors.New("no player")
+		}
+		potions := core.DefaultPotions()
+		player["potions"] = map[string]any{"Count": potions.Count, "Max": potions.Max}
+		return nil
+	},
+	// 2 -> 3: the position, stats and potions were written with the Go field
+	// names of core types, they get json names of their own
+	2: func(save map[string]any) error {
+		player, ok := save["player"].(map[string]any)
+		if !ok {
+			return errors.New("no player")
+		}
+		renameKeys(player["pos"], map[string]string{"X": "x", "Y": "y"})
+		renameKeys(player["combat"], map[string]string{"Health": "health", "MaxHealth": "maxHealth", "Power": "power", "MaxPower": "maxPower"})
+		renameKeys(player["potions"], map[string]string{"Count": "count", "Max": "max"})
+		return nil
+	},
+}
+
+// renameKeys renames the keys of a decoded JSON object, anything else is left alone
+func renameKeys(obj any, names map[string]string) {
+	m, ok := obj.(map[string]any)
+	if !ok {
+		return
+	}
+	for from, to := range names {
+		if v, ok := m[from]; ok {
+			delete(m, from)
+			m[to] = v
+		}
+	}
+}
+
+// migrateSave walks a decoded save up to SaveVersion
+func migrateSave(save map[string]any) error {
+	version := 0
+	if v, ok := save["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > SaveVersion {
+		return fmt.Errorf("save version %d is newer than this build (%d)", version, SaveVersion)
+	}
+
+	for ; version < SaveVersion; version++ {
+		migrate, ok := saveMigrations[version]
+		if !ok {
+			return fmt.Errorf("no migration from save version %d", version)
+		}
+		if err := migrate(save); err != nil {
+			return fmt.Errorf("migrate save from version %d: %w", version, err)
+		}
+	}
+	save["version"] = SaveVersion
+	return nil
+}
+
+// ---------------- world <-> save ----------------
+
+// Snapshot captures the world into a save for slot
+func (w *World) Snapshot(slot int) *SaveFile {
+	p := w.Player
+	save := &SaveFile{
+		Version: SaveVersion,
+		Slot:    slot,
+		SavedAt: time.Now(),
+		LevelID: w.LevelID,
+		Score:   w.Score,
+		Tick:    w.Tick,
+		Player: PlayerSave{
+			Pos:   PositionSave{X: p.Pos.X, Y: p.Pos.Y},
+			VelX:  p.Physics.VelX,
+			VelY:  p.Physics.VelY,
+			FlipX: p.FlipX,
+			Combat: CombatSave{
+				Health:    p.Combat.Health,
+				MaxHealth: p.Combat.MaxHealth,
+				Power:     p.Combat.Power,
+				MaxPower:  p.Combat.MaxPower,
+			},
+			Potions:      PotionsSave{Count: p.Potions.Count, Max: p.Potions.Max},
+			CheckpointID: p.CheckpointID,
+		},
+	}
+	for _, cp := range w.Checkpoints {
+		if cp.Active {
+			save.ActiveCheckpoints = append(save.ActiveCheckpoints, cp.ID)
+		}
+	}
+	for _, m := range w.Enemies.EnemyManager {
+		save.Enemies = append(save.Enemies, EnemyManagerSave{
+			ID:               m.ID,
+			TotalKills:       m.TotalKills,
+			TotalDeaths:      m.TotalDeaths,
+			TotalDamageDealt: m.TotalDamageDealt,
+			TotalDamageTaken: m.TotalDamageTaken,
+		})
+	}
+	return save
+}
+
+// Restore loads the level of save and puts the player, checkpoints, score
+// and enemy statistics back the way they were
+func (w *World) Restore(save *SaveFile) error {
+	info, err := LevelByID(save.LevelID)
+	if err != nil {
+		return err
+	}
+	if err := w.EnterLevel(info); err != nil {
+		return err
+	}
+
+	w.Score = save.Score
+	w.Tick = save.Tick
+	w.CampaignComplete = false
+
+	for _, id := range save.ActiveCheckpoints {
+		if cp, ok := w.Checkpoint(id); ok {
+			cp.Active = true
+		}
+	}
+
+	p := w.Player
+	p.Reset(core.Position{X: save.Player.Pos.X, Y: save.Player.Pos.Y})
+	p.Physics.VelX = save.Player.VelX
+	p.Physics.VelY = save.Player.VelY
+	p.FlipX = save.Player.FlipX
+	// the stats only, the regen rate belongs to the character and not the save
+	p.Combat.Health, p.Combat.MaxHealth = save.Player.Combat.Health, save.Player.Combat.MaxHealth
+	p.Combat.Power, p.Combat.MaxPower = save.Player.Combat.Power, save.Player.Combat.MaxPower
+	p.Potions = core.Potions{Count: save.Player.Potions.Count, Max: save.Player.Potions.Max}
+	p.CheckpointID = save.Player.CheckpointID
+	if _, ok := w.Checkpoint(p.CheckpointID); !ok {
+		p.CheckpointID = core.DefaultCheckpointID
+	}
+
+	// managers are matched by ID, the level may have been edited since
+	for _, stats := range save.Enemies {
+		for i := range w.Enemies.EnemyManager {
+			m := &w.Enemies.EnemyManager[i]
+			if m.ID != stats.ID {
+				continue
+			}
+			m.TotalKills = stats.TotalKills
+			m.TotalDeaths = stats.TotalDeaths
+			m.TotalDamageDealt = stats.TotalDamageDealt
+			m.TotalDamageTaken = stats.TotalDamageTaken
+		}
+	}
+	return nil
+}
+
+// ---------------- slots ----------------
+
+// SlotPath is the file a slot is saved to
+func SlotPath(slot int) string {
+	return filepath.Join(SaveDir, fmt.Sprintf("slot_%d.json", slot))
+}
+
+// SaveGame writes the world to a save slot
+func (w *World) SaveGame(slot int) error {
+	data, err := json.MarshalIndent(w.Snapshot(slot), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(SaveDir, 0o755); err != nil {
+		return err
+	}
+
+	// write next to the slot and rename, a crash mid-write must not eat the old save
+	path := SlotPath(slot)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadGame restores the world from a save slot
+func (w *World) LoadGame(slot int) error {
+	save, err := ReadSave(SlotPath(slot))
+	if err != nil {
+		return err
+	}
+	return w.Restore(save)
+}
+
+// ReadSave reads and migrates a save file
+func ReadSave(path string) (*SaveFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("read save %s: %w", path, err)
+	}
+	if err := migrateSave(raw); err != nil {
+		return nil, fmt.Errorf("read save %s: %w", path, err)
+	}
+
+	// round trip the migrated map into the current struct
+	if data, err = json.Marshal(raw); err != nil {
+		return nil, err
+	}
+	save := &SaveFile{}
+	if err := json.Unmarshal(data, save); err != nil {
+		return nil, fmt.Errorf("read save %s: %w", path, err)
+	}
+	return save, nil
+}
+
+// ListSaves returns the save in every used slot, lowest slot first.
+// Unreadable slots are skipped.
+func ListSaves() ([]*SaveFile, error) {
+	paths, err := filepath.Glob(filepath.Join(SaveDir, "slot_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var saves []*SaveFile
+	for _, path := range paths {
+		save, err := ReadSave(path)
+		if err != nil {
+			continue
+		}
+		saves = append(saves, save)
+	}
+	sort.Slice(saves, func(i, j int) bool { return saves[i].Slot < saves[j].Slot })
+	return saves, nil
+}
+
+// SlotExists reports whether a slot has a save in it
+func SlotExists(slot int) bool {
+	_, err := os.Stat(SlotPath(slot))
+	return !errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,183 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"player/internal/core"
+)
+
+// writeSave writes save to a file in a temporary directory
+func writeSave(t *testing.T, save any) string {
+	t.Helper()
+	data, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "slot_1.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// useSaveDir points SaveDir at a temporary directory for the length of the test
+func useSaveDir(t *testing.T) {
+	t.Helper()
+	saved := SaveDir
+	SaveDir = t.TempDir()
+	t.Cleanup(func() { SaveDir = saved })
+}
+
+func TestRestoreKeepsPowerRegen(t *testing.T) {
+	w := newFixtureWorld(t)
+	w.Player.Combat.Power = 50
+	save, err := ReadSave(writeSave(t, w.Snapshot(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Player.Combat.Power = 0
+	if err := w.Restore(save); err != nil {
+		t.Fatal(err)
+	}
+	if w.Player.Combat.Power != 50 {
+		t.Fatalf("power = %v after loading, want 50", w.Player.Combat.Power)
+	}
+	if w.Player.Combat.PowerRegen != core.PlayerPowerRegen {
+		t.Fatalf("PowerRegen = %v after loading, want %v", w.Player.Combat.PowerRegen, core.PlayerPowerRegen)
+	}
+	if err := w.Run(core.TickRate, nil); err != nil {
+		t.Fatal(err)
+	}
+	if w.Player.Combat.Power <= 50 {
+		t.Fatalf("power = %v a second after loading, regen stopped", w.Player.Combat.Power)
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	useSaveDir(t)
+	w := newFixtureWorld(t)
+	if err := w.Run(core.TickRate/2, nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Score = 1234
+	w.Player.Combat.Health = 42
+	w.Player.Combat.Power = 17
+	w.Player.Potions.Count = 1
+	w.Player.FlipX = true
+	want := w.Snapshot(2)
+
+	if err := w.SaveGame(2); err != nil {
+		t.Fatal(err)
+	}
+	if !SlotExists(2) || SlotExists(3) {
+		t.Fatal("SlotExists does not match the slots written")
+	}
+
+	// scramble the world, then load it back
+	w.Score = 0
+	w.Player.Combat = core.Combat{PowerRegen: core.PlayerPowerRegen}
+	w.Player.Potions = core.Potions{}
+	w.Player.Reset(core.Position{})
+	if err := w.LoadGame(2); err != nil {
+		t.Fatal(err)
+	}
+	got := w.Snapshot(2)
+	got.SavedAt = want.SavedAt
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("after loading:\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
+
+// v1Save is a save from before the potion inventory, with the Go field names
+// early versions wrote
+const v1Save = `{
+  "version": 1, "slot": 1, "levelId": "fixture_1", "score": 50,
+  "player": {"pos": {"X": 10, "Y": 20}, "combat": {"Health": 30, "MaxHealth": 100, "Power": 40, "MaxPower": 100}, "checkpointId": "default"}
+}`
+
+const v2Save = `{
+  "version": 2, "slot": 1, "levelId": "fixture_1", "score": 50,
+  "player": {"pos": {"X": 10, "Y": 20}, "combat": {"Health": 30, "MaxHealth": 100, "Power": 40, "MaxPower": 100},
+    "potions": {"Count": 1, "Max": 5}, "checkpointId": "default"}
+}`
+
+func TestReadSave(t *testing.T) {
+	defaults := core.DefaultPotions()
+	tests := []struct {
+		name        string
+		data        string
+		wantErr     string
+		wantPotions PotionsSave
+	}{
+		{"version 1 gets the default potions", v1Save, "", PotionsSave{Count: defaults.Count, Max: defaults.Max}},
+		{"version 2 keeps its potions", v2Save, "", PotionsSave{Count: 1, Max: 5}},
+		{"newer version", `{"version": 99, "player": {}}`, "newer than this build", PotionsSave{}},
+		{"no version", `{"player": {}}`, "no migration from save version 0", PotionsSave{}},
+		{"version 1 without a player", `{"version": 1}`, "no player", PotionsSave{}},
+		{"not json", `{"version": 3,`, "read save", PotionsSave{}},
+		{"wrong types", `{"version": 3, "score": "lots"}`, "read save", PotionsSave{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "slot_1.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			save, err := ReadSave(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if save.Version != SaveVersion {
+				t.Fatalf("version = %d, want %d", save.Version, SaveVersion)
+			}
+			p := save.Player
+			if p.Pos != (PositionSave{X: 10, Y: 20}) || p.Combat != (CombatSave{Health: 30, MaxHealth: 100, Power: 40, MaxPower: 100}) {
+				t.Fatalf("player = %+v, the old field names did not carry over", p)
+			}
+			if p.Potions != tt.wantPotions {
+				t.Fatalf("potions = %+v, want %+v", p.Potions, tt.wantPotions)
+			}
+		})
+	}
+}
+
+func TestReadSaveMissing(t *testing.T) {
+	if _, err := ReadSave(filepath.Join(t.TempDir(), "slot_1.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("err = %v, want a missing file", err)
+	}
+}
+
+func TestListSaves(t *testing.T) {
+	useSaveDir(t)
+	w := newFixtureWorld(t)
+	for _, slot := range []int{3, 1} {
+		if err := w.SaveGame(slot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(SlotPath(2), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	saves, err := ListSaves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saves) != 2 || saves[0].Slot != 1 || saves[1].Slot != 3 {
+		t.Fatalf("got %d saves, want slots 1 and 3 in order, the broken slot skipped", len(saves))
+	}
+}
@@ -0,0 +1,253 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"player/internal/core"
+)
+
+const (
+	OptionStart  = "Start"
+	OptionResume = "Resume"
+	OptionRetry  = "Retry"
+	OptionQuit   = "Quit"
+	OptionTitle  = "Quit to title"
+)
+
+// ErrQuit is returned from Update when quit is picked on the title menu
+var ErrQuit = errors.New("quit")
+
+// Session is what the scenes share: the world and, when a recording is played
+// back, the replayer feeding it. The hooks let the driver react, any can be nil.
+type Session struct {
+	World     *World
+	Recording *Recording // recording played back instead of live input
+	replayer  *Replayer
+
+	OnLevelLoaded func()           // the world is on another level, eg: to load its background
+	OnMessage     func(msg string) // something to tell the player, eg: "Game saved to slot 0"
+
+	respawn      bool // retry was picked on the game over screen, the next step respawns
+	campaignDone bool
+}
+
+func NewSession(world *World) *Session {
+	return &Session{World: world}
+}
+
+// Replay plays rec back instead of live input, at the rate it was recorded at
+func (s *Session) Replay(rec *Recording) {
+	s.Recording = rec
+	s.replayer = NewReplayer(rec)
+	s.World.Clock = core.NewSimClock(rec.TickRate)
+}
+
+// Replaying reports whether the input comes from a recording
+func (s *Session) Replaying() bool {
+	return s.replayer != nil
+}
+
+func (s *Session) levelLoaded() {
+	if s.OnLevelLoaded != nil {
+		s.OnLevelLoaded()
+	}
+}
+
+func (s *Session) message(format string, args ...any) {
+	if s.OnMessage != nil {
+		s.OnMessage(fmt.Sprintf(format, args...))
+	}
+}
+
+// SaveGame writes the world to a save slot
+func (s *Session) SaveGame(slot int) {
+	if err := s.World.SaveGame(slot); err != nil {
+		s.message("save game: %v", err)
+		return
+	}
+	s.message("Game saved to slot %d", slot)
+}
+
+// LoadGame restores the world from a save slot
+func (s *Session) LoadGame(slot int) error {
+	if err := s.World.LoadGame(slot); err != nil {
+		return err
+	}
+	s.levelLoaded()
+	s.campaignDone = false
+	s.message("Game loaded from slot %d", slot)
+	return nil
+}
+
+// nextReplayInput loads the input of the next recorded tick, and checks the
+// end state against the recording once it runs out
+func (s *Session) nextReplayInput(input *core.InputState) {
+	if s.replayer.Next(input) {
+		return
+	}
+	if s.Recording.FinalHash != 0 {
+		if s.World.Hash() == s.Recording.FinalHash {
+			s.message("Replay finished, end state matches the recording")
+		} else {
+			s.message("Replay finished, end state DIVERGED from the recording")
+		}
+	}
+	s.replayer = nil
+	s.Recording = nil
+}
+
+// ---------------- title menu ----------------
+
+type MenuScene struct {
+	s    *Session
+	Menu *Menu
+}
+
+func NewMenuScene(s *Session) *MenuScene {
+	return &MenuScene{s: s, Menu: NewMenu(OptionStart, OptionQuit)}
+}
+
+func (m *MenuScene) Mode() core.GameState { return core.ModeMenu }
+
+func (m *MenuScene) Update(sm *StateMachine, input *core.InputState, dt float64) error {
+	switch m.Menu.Update(input) {
+	case OptionStart:
+		// the level was loaded at startup, start it fresh
+		w := m.s.World
+		if err := w.Restart(); err != nil {
+			return err
+		}
+		// a recording starts from a fresh level, so quitting to the title starts it over
+		if w.Recorder != nil {
+			w.Recorder = NewRecorder(w)
+		}
+		m.s.levelLoaded()
+		sm.Replace(NewPlayingScene(m.s))
+	case OptionQuit:
+		return ErrQuit
+	}
+	return nil
+}
+
+// ---------------- playing ----------------
+
+type PlayingScene struct {
+	s *Session
+}
+
+func NewPlayingScene(s *Session) *PlayingScene {
+	return &PlayingScene{s: s}
+}
+
+func (p *PlayingScene) Mode() core.GameState { return core.ModePlaying }
+
+func (p *PlayingScene) Update(sm *StateMachine, input *core.InputState, dt float64) error {
+	s := p.s
+	w := s.World
+
+	// a replay drives the input itself, there is no keyboard to unpause with
+	if input.Menu && !s.Replaying() {
+		sm.Push(NewPauseScene(s))
+		return nil
+	}
+
+	// loading jumps the world somewhere a recording can't follow
+	if input.QuickSave {
+		s.SaveGame(QuickSlot)
+	}
+	if input.QuickLoad && !s.Replaying() && w.Recorder == nil {
+		if err := s.LoadGame(QuickSlot); err != nil {
+			s.message("load game: %v", err)
+		}
+	}
+
+	// feed real frame time into the clock and run as many fixed steps as it hands back
+	steps := w.Clock.Advance(dt)
+	for i := 0; i < steps; i++ {
+		if s.Replaying() {
+			s.nextReplayInput(input)
+		}
+		if s.respawn {
+			input.Respawn = true
+			s.respawn = false
+		}
+		w.Step(input)
+
+		// the player walked into an exit, swap levels between steps
+		changed, err := w.AdvanceLevel()
+		if err != nil {
+			return err
+		}
+		if changed {
+			s.levelLoaded()
+			s.message("Level loaded: %s", w.LevelID)
+			break
+		}
+	}
+
+	if w.CampaignComplete && !s.campaignDone {
+		s.message("Campaign complete")
+		s.campaignDone = true
+	}
+
+	// let the dead animation play out first. A replay respawns from the
+	// recorded input instead, and a picked retry waits for the next step.
+	if w.GameOver() && !s.Replaying() && !s.respawn {
+		sm.Push(NewGameOverScene(s))
+	}
+	return nil
+}
+
+// ---------------- pause ----------------
+
+// PauseScene sits on top of the playing scene, which does not update while it is open
+type PauseScene struct {
+	s    *Session
+	Menu *Menu
+}
+
+func NewPauseScene(s *Session) *PauseScene {
+	return &PauseScene{s: s, Menu: NewMenu(OptionResume, OptionTitle)}
+}
+
+func (p *PauseScene) Mode() core.GameState { return core.ModePaused }
+
+func (p *PauseScene) Update(sm *StateMachine, input *core.InputState, dt float64) error {
+	if input.Menu {
+		sm.Pop()
+		return nil
+	}
+	switch p.Menu.Update(input) {
+	case OptionResume:
+		sm.Pop()
+	case OptionTitle:
+		sm.Reset(NewMenuScene(p.s))
+	}
+	return nil
+}
+
+// ---------------- game over ----------------
+
+type GameOverScene struct {
+	s    *Session
+	Menu *Menu
+}
+
+func NewGameOverScene(s *Session) *GameOverScene {
+	return &GameOverScene{s: s, Menu: NewMenu(OptionRetry, OptionTitle)}
+}
+
+func (g *GameOverScene) Mode() core.GameState { return core.ModeGameOver }
+
+func (g *GameOverScene) Update(sm *StateMachine, input *core.InputState, dt float64) error {
+	switch g.Menu.Update(input) {
+	case OptionRetry:
+		// the world respawns the player on its next step, where a recording sees it
+		g.s.respawn = true
+		sm.Pop()
+	case OptionTitle:
+		sm.Reset(NewMenuScene(g.s))
+	}
+	return nil
+}
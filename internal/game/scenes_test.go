@@ -0,0 +1,147 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"player/internal/core"
+)
+
+// frameDt is one frame at the tick rate, the clock runs one step for it
+const frameDt = 1.0 / core.TickRate
+
+// frames updates sm n times with the same input
+func frames(t *testing.T, sm *StateMachine, n int, input core.InputState) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		in := input
+		if err := sm.Update(&in, frameDt); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMenuSceneStart(t *testing.T) {
+	w := newFixtureWorld(t)
+	s := NewSession(w)
+	loaded := 0
+	s.OnLevelLoaded = func() { loaded++ }
+
+	spawnX := w.Player.Pos.X
+	if err := w.Run(30, holdRight); err != nil {
+		t.Fatal(err)
+	}
+	sm := NewStateMachine(NewMenuScene(s))
+	frames(t, sm, 1, core.InputState{Confirm: true})
+
+	if !sameModes(modes(sm), []core.GameState{core.ModePlaying}) {
+		t.Fatalf("modes = %v, want playing only", modes(sm))
+	}
+	if loaded != 1 {
+		t.Errorf("level loaded hook ran %d times, want 1", loaded)
+	}
+	if x := w.Player.Pos.X; x != spawnX {
+		t.Errorf("start did not restart the level, player at x %v, spawn at %v", x, spawnX)
+	}
+}
+
+func TestMenuSceneQuit(t *testing.T) {
+	sm := NewStateMachine(NewMenuScene(NewSession(newTestWorld(t))))
+	frames(t, sm, 1, core.InputState{Direction: core.Direction{UpDown: -1}})
+
+	in := core.InputState{Confirm: true}
+	if err := sm.Update(&in, frameDt); !errors.Is(err, ErrQuit) {
+		t.Fatalf("quit returned %v, want ErrQuit", err)
+	}
+}
+
+func TestPauseFreezesWorld(t *testing.T) {
+	w := newTestWorld(t)
+	sm := NewStateMachine(NewPlayingScene(NewSession(w)))
+
+	frames(t, sm, 10, core.InputState{})
+	if w.Tick != 10 {
+		t.Fatalf("tick = %d after 10 frames, want 10", w.Tick)
+	}
+
+	frames(t, sm, 1, core.InputState{Menu: true})
+	if sm.Mode() != core.ModePaused {
+		t.Fatalf("mode = %v after menu, want paused", sm.Mode())
+	}
+	frames(t, sm, 30, holdRight(0))
+	if w.Tick != 10 {
+		t.Fatalf("world stepped while paused, tick = %d", w.Tick)
+	}
+
+	// resume is the first option
+	frames(t, sm, 1, core.InputState{Confirm: true})
+	if sm.Mode() != core.ModePlaying {
+		t.Fatalf("mode = %v after resume, want playing", sm.Mode())
+	}
+	frames(t, sm, 5, core.InputState{})
+	if w.Tick != 15 {
+		t.Fatalf("tick = %d after resuming for 5 frames, want 15", w.Tick)
+	}
+}
+
+func TestPauseQuitToTitle(t *testing.T) {
+	sm := NewStateMachine(NewPlayingScene(NewSession(newTestWorld(t))))
+	frames(t, sm, 1, core.InputState{Menu: true})
+	frames(t, sm, 1, core.InputState{Direction: core.Direction{UpDown: -1}})
+	frames(t, sm, 1, core.InputState{Confirm: true})
+
+	if !sameModes(modes(sm), []core.GameState{core.ModeMenu}) {
+		t.Fatalf("modes = %v, want the title menu only", modes(sm))
+	}
+}
+
+func TestGameOverRetry(t *testing.T) {
+	w := newTestWorld(t)
+	w.LoadLevel("pit", pitLevel(t))
+	sm := NewStateMachine(NewPlayingScene(NewSession(w)))
+
+	// falling out of the pit takes a second or two, then the dead animation plays out
+	limit := int(math.Ceil((RespawnDelay + 3) * core.TickRate))
+	for i := 0; i < limit && sm.Mode() != core.ModeGameOver; i++ {
+		frames(t, sm, 1, core.InputState{})
+	}
+	if sm.Mode() != core.ModeGameOver {
+		t.Fatal("game over scene never came up")
+	}
+	tick := w.Tick
+
+	// the game over screen freezes the world like the pause menu
+	frames(t, sm, 10, core.InputState{})
+	if w.Tick != tick {
+		t.Fatalf("world stepped under the game over screen, tick = %d, want %d", w.Tick, tick)
+	}
+
+	// retry is the first option, the player comes back on the next step
+	frames(t, sm, 1, core.InputState{Confirm: true})
+	if sm.Mode() != core.ModePlaying {
+		t.Fatalf("mode = %v after retry, want playing", sm.Mode())
+	}
+	if !w.PlayerDead() {
+		t.Fatal("retry respawned before the world stepped")
+	}
+	frames(t, sm, 1, core.InputState{})
+	if w.PlayerDead() {
+		t.Fatal("retry did not respawn the player")
+	}
+	if sm.Mode() != core.ModePlaying {
+		t.Fatalf("mode = %v after the respawn, want playing", sm.Mode())
+	}
+}
+
+func TestGameOverQuitToTitle(t *testing.T) {
+	w := newTestWorld(t)
+	sm := NewStateMachine(NewPlayingScene(NewSession(w)))
+	sm.Push(NewGameOverScene(sm.Top().(*PlayingScene).s))
+
+	frames(t, sm, 1, core.InputState{Direction: core.Direction{UpDown: -1}})
+	frames(t, sm, 1, core.InputState{Confirm: true})
+	if !sameModes(modes(sm), []core.GameState{core.ModeMenu}) {
+		t.Fatalf("modes = %v, want the title menu only", modes(sm))
+	}
+}
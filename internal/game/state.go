@@ -0,0 +1,100 @@
+package game
+
+import "player/internal/core"
+
+// Scene is one mode of the game (title menu, playing, pause, game over).
+// Scenes are kept on a stack: only the top one updates. Drawing is up to the
+// renderer, which walks Scenes from the bottom up so an overlay like the
+// pause menu shows the game behind it.
+type Scene interface {
+	Mode() core.GameState
+	Update(sm *StateMachine, input *core.InputState, dt float64) error
+}
+
+// StateMachine is the scene stack. It knows nothing about rendering, so scene
+// logic can be driven from a test with no window.
+type StateMachine struct {
+	stack []Scene
+}
+
+func NewStateMachine(initial Scene) *StateMachine {
+	return &StateMachine{stack: []Scene{initial}}
+}
+
+// Push puts s on top, eg: the pause overlay over the game
+func (sm *StateMachine) Push(s Scene) {
+	sm.stack = append(sm.stack, s)
+}
+
+// Pop removes the top scene. The last scene is never popped.
+func (sm *StateMachine) Pop() Scene {
+	if len(sm.stack) <= 1 {
+		return nil
+	}
+	top := sm.stack[len(sm.stack)-1]
+	sm.stack = sm.stack[:len(sm.stack)-1]
+	return top
+}
+
+// Replace swaps the top scene for s
+func (sm *StateMachine) Replace(s Scene) {
+	sm.stack[len(sm.stack)-1] = s
+}
+
+// Reset drops every scene and starts over with s, eg: quitting to the title
+func (sm *StateMachine) Reset(s Scene) {
+	sm.stack = append(sm.stack[:0], s)
+}
+
+func (sm *StateMachine) Top() Scene {
+	return sm.stack[len(sm.stack)-1]
+}
+
+// Mode is the mode of the top scene
+func (sm *StateMachine) Mode() core.GameState {
+	return sm.Top().Mode()
+}
+
+// Scenes returns the stack, bottom first
+func (sm *StateMachine) Scenes() []Scene {
+	return sm.stack
+}
+
+// Update runs the top scene only, which is what freezes everything under an
+// overlay. dt is the real time since the last frame, in seconds.
+func (sm *StateMachine) Update(input *core.InputState, dt float64) error {
+	return sm.Top().Update(sm, input, dt)
+}
+
+// ---------------- menu ----------------
+
+// Menu is a vertical list of options navigated with up / down and picked with confirm
+type Menu struct {
+	Options  []string
+	Selected int
+
+	lastUpDown int8 // up / down is a held input, only move on the press
+}
+
+func NewMenu(options ...string) *Menu {
+	return &Menu{Options: options}
+}
+
+// Update moves the selection and returns the picked option, or "" if nothing was picked this frame
+func (m *Menu) Update(input *core.InputState) string {
+	upDown := input.Direction.UpDown
+	if upDown != m.lastUpDown && len(m.Options) > 0 {
+		switch upDown {
+		case 1: // up
+			m.Selected = (m.Selected - 1 + len(m.Options)) % len(m.Options)
+		case -1: // down
+			m.Selected = (m.Selected + 1) % len(m.Options)
+		}
+	}
+	m.lastUpDown = upDown
+
+	if input.Confirm && len(m.Options) > 0 {
+		return m.Options[m.Selected]
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package game
+
+import (
+	"testing"
+
+	"player/internal/core"
+)
+
+// fakeScene counts its updates and runs an optional action on the stack
+type fakeScene struct {
+	mode    core.GameState
+	updates int
+	action  func(sm *StateMachine)
+}
+
+func (s *fakeScene) Mode() core.GameState { return s.mode }
+
+func (s *fakeScene) Update(sm *StateMachine, input *core.InputState, dt float64) error {
+	s.updates++
+	if s.action != nil {
+		s.action(sm)
+	}
+	return nil
+}
+
+func modes(sm *StateMachine) []core.GameState {
+	var out []core.GameState
+	for _, s := range sm.Scenes() {
+		out = append(out, s.Mode())
+	}
+	return out
+}
+
+func sameModes(a, b []core.GameState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStateMachineTransitions(t *testing.T) {
+	menu := &fakeScene{mode: core.ModeMenu}
+	playing := &fakeScene{mode: core.ModePlaying}
+	paused := &fakeScene{mode: core.ModePaused}
+	gameOver := &fakeScene{mode: core.ModeGameOver}
+
+	tests := []struct {
+		name string
+		do   func(sm *StateMachine)
+		want []core.GameState
+	}{
+		{"start", func(sm *StateMachine) {}, []core.GameState{core.ModeMenu}},
+		{"replace", func(sm *StateMachine) { sm.Replace(playing) }, []core.GameState{core.ModePlaying}},
+		{"push", func(sm *StateMachine) { sm.Replace(playing); sm.Push(paused) }, []core.GameState{core.ModePlaying, core.ModePaused}},
+		{"pop", func(sm *StateMachine) { sm.Replace(playing); sm.Push(paused); sm.Pop() }, []core.GameState{core.ModePlaying}},
+		{"last scene stays", func(sm *StateMachine) { sm.Pop(); sm.Pop() }, []core.GameState{core.ModeMenu}},
+		{"replace overlay", func(sm *StateMachine) { sm.Replace(playing); sm.Push(paused); sm.Replace(gameOver) }, []core.GameState{core.ModePlaying, core.ModeGameOver}},
+		{"reset", func(sm *StateMachine) { sm.Replace(playing); sm.Push(paused); sm.Reset(menu) }, []core.GameState{core.ModeMenu}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStateMachine(menu)
+			tt.do(sm)
+			if got := modes(sm); !sameModes(got, tt.want) {
+				t.Fatalf("stack = %v, want %v", got, tt.want)
+			}
+			if got, want := sm.Mode(), tt.want[len(tt.want)-1]; got != want {
+				t.Fatalf("Mode() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStateMachinePopLastReturnsNil(t *testing.T) {
+	sm := NewStateMachine(&fakeScene{mode: core.ModeMenu})
+	if s := sm.Pop(); s != nil {
+		t.Fatalf("Pop() on the last scene = %v, want nil", s)
+	}
+}
+
+func TestStateMachineUpdatesTopOnly(t *testing.T) {
+	playing := &fakeScene{mode: core.ModePlaying}
+	paused := &fakeScene{mode: core.ModePaused}
+	sm := NewStateMachine(playing)
+	sm.Push(paused)
+
+	var input core.InputState
+	for i := 0; i < 3; i++ {
+		if err := sm.Update(&input, frameDt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if playing.updates != 0 || paused.updates != 3 {
+		t.Fatalf("updates: playing %d, paused %d, want 0 and 3", playing.updates, paused.updates)
+	}
+}
+
+func TestStateMachineSceneDrivesTransition(t *testing.T) {
+	playing := &fakeScene{mode: core.ModePlaying}
+	paused := &fakeScene{mode: core.ModePaused, action: func(sm *StateMachine) { sm.Pop() }}
+	playing.action = func(sm *StateMachine) { sm.Push(paused) }
+	sm := NewStateMachine(playing)
+
+	var input core.InputState
+	want := []core.GameState{core.ModePaused, core.ModePlaying, core.ModePaused}
+	for i, mode := range want {
+		if err := sm.Update(&input, frameDt); err != nil {
+			t.Fatal(err)
+		}
+		if sm.Mode() != mode {
+			t.Fatalf("after update %d: mode %v, want %v", i+1, sm.Mode(), mode)
+		}
+	}
+}
+
+func TestMenuNavigation(t *testing.T) {
+	const (
+		up   = 1
+		down = -1
+	)
+	tests := []struct {
+		name         string
+		upDown       []int8 // held direction every frame
+		wantSelected int
+	}{
+		{"down", []int8{down}, 1},
+		{"held down moves once", []int8{down, down, down}, 1},
+		{"down twice", []int8{down, 0, down}, 2},
+		{"up wraps to the last", []int8{up}, 2},
+		{"down wraps to the first", []int8{down, 0, down, 0, down}, 0},
+		{"up then down", []int8{up, down}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMenu("a", "b", "c")
+			for _, ud := range tt.upDown {
+				input := core.InputState{Direction: core.Direction{UpDown: ud}}
+				if picked := m.Update(&input); picked != "" {
+					t.Fatalf("picked %q without confirm", picked)
+				}
+			}
+			if m.Selected != tt.wantSelected {
+				t.Fatalf("Selected = %d, want %d", m.Selected, tt.wantSelected)
+			}
+		})
+	}
+}
+
+func TestMenuConfirm(t *testing.T) {
+	m := NewMenu("Start", "Quit")
+	m.Update(&core.InputState{Direction: core.Direction{UpDown: -1}})
+	if picked := m.Update(&core.InputState{Confirm: true}); picked != "Quit" {
+		t.Fatalf("picked %q, want Quit", picked)
+	}
+
+	empty := NewMenu()
+	if picked := empty.Update(&core.InputState{Confirm: true}); picked != "" {
+		t.Fatalf("empty menu picked %q", picked)
+	}
+}
@@ -0,0 +1,280 @@
+package game
+
+import (
+	"fmt"
+
+	"player/assets"
+	"player/enemy"
+	"player/internal/core"
+)
+
+// default camera viewport used when the world is not attached to a window
+const (
+	DefaultViewWidth  = 1360
+	DefaultViewHeight = 768
+)
+
+// World is the simulation state of the loaded level: platforms, the spatial
+// index, the player and the enemies. It owns no window or GPU resources, so
+// it can be stepped from a test or a CLI just as well as from ebiten.
+type World struct {
+	LevelID       string
+	Level         []core.Platform
+	Dynamic       []*core.Platform   // moving and crumbling platforms, updated every tick
+	Exits         []core.EntitySpawn // touching one of these ends the level
+	Spawn         core.Position      // where the player starts in this level
+	Checkpoints   []Checkpoint       // respawn points, the level spawn point included
+	Width, Height float64            // level size in world units
+	Quadtree      *core.DynamicQuadtree
+	Player        *core.PlayerRuntime
+	Enemies       *enemy.ParallelEnemyManager
+	Projectiles   *core.Projectiles
+	Clock         *core.SimClock
+
+	// camera viewport passed to UpdateCamera
+	ViewWidth, ViewHeight float64
+
+	Tick uint64 // simulation steps taken since the world was created
+
+	// level transitions, the driver calls AdvanceLevel between steps
+	PendingLevel     string // level the player walked into
+	CampaignComplete bool   // the player left the last level
+
+	DeathTimer float64 // seconds since the player died
+	Score      int
+
+	// determinism: the simulation draws no random numbers, the level and
+	// the input stream decide everything
+	Recorder *Recorder // when set, every input fed to Step is recorded
+}
+
+// NewWorld creates a world with no level loaded. If enemies is nil a headless,
+// sequential enemy manager is created.
+func NewWorld(player *core.PlayerRuntime, enemies *enemy.ParallelEnemyManager) (*World, error) {
+	if enemies == nil {
+		var err error
+		if enemies, err = enemy.HeadlessParallelConfig(player, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	w := &World{
+		Player:      player,
+		Enemies:     enemies,
+		Projectiles: core.NewProjectiles(),
+		Clock:       core.NewSimClock(core.TickRate),
+		ViewWidth:   DefaultViewWidth,
+		ViewHeight:  DefaultViewHeight,
+	}
+	return w, nil
+}
+
+// LoadLevel unloads the current level and builds the world from level
+func (w *World) LoadLevel(id string, level *core.LevelData) {
+	w.Unload()
+
+	w.LevelID = id
+	w.Width, w.Height = level.Width, level.Height
+	w.Quadtree = core.NewDynamicQuadtree(core.AABB{X: 0, Y: 0, Width: w.Width, Height: w.Height})
+
+	w.Level = level.Platforms
+	for i := range w.Level {
+		w.Quadtree.Insert(&w.Level[i])
+		if w.Level[i].IsDynamic() {
+			w.Dynamic = append(w.Dynamic, &w.Level[i])
+		}
+	}
+	w.Enemies.AddEnemyToLevel(level.Spawns)
+
+	for _, spawn := range level.Spawns {
+		if spawn.Kind == core.EntityExit {
+			w.Exits = append(w.Exits, spawn)
+		}
+	}
+
+	// stand the player on the spawn point, feet on the bottom of the marker
+	w.Spawn = core.Position{X: core.DefaultSpawnX, Y: core.DefaultSpawnY}
+	if spawn, ok := level.FirstSpawn(core.EntitySpawnPoint); ok {
+		w.Spawn = core.Position{X: spawn.X, Y: spawn.Y + spawn.Height - w.Player.GetBounds().Height}
+	}
+	w.Player.Reset(w.Spawn)
+	w.Player.CheckpointID = core.DefaultCheckpointID
+	w.buildCheckpoints(level)
+	w.DeathTimer = 0
+
+	w.Clock.Reset()
+}
+
+// EnterLevel loads a registered level
+func (w *World) EnterLevel(info LevelInfo) error {
+	level, err := core.LoadLevelFile(assets.Path(info.MapPath))
+	if err != nil {
+		return fmt.Errorf("level %s: %w", info.ID, err)
+	}
+	w.LoadLevel(info.ID, level)
+	return nil
+}
+
+// AdvanceLevel loads the level the player walked into, if there is one.
+// It reports whether the level changed.
+func (w *World) AdvanceLevel() (bool, error) {
+	if w.PendingLevel == "" {
+		return false, nil
+	}
+	next := w.PendingLevel
+	w.PendingLevel = ""
+
+	info, err := LevelByID(next)
+	if err != nil {
+		return false, err
+	}
+	return true, w.EnterLevel(info)
+}
+
+// Restart reloads the current level from scratch with the player at full health
+// and potions refilled, eg: retrying from the game over screen
+func (w *World) Restart() error {
+	info, err := LevelByID(w.LevelID)
+	if err != nil {
+		return err
+	}
+	w.Player.Combat.Health = w.Player.Combat.MaxHealth
+	w.Player.Potions.Refill()
+	w.CampaignComplete = false
+	return w.EnterLevel(info)
+}
+
+// Unload clears the current level: the quadtree, the enemies and their
+// workers, and the projectiles in flight
+func (w *World) Unload() {
+	w.Projectiles.Clear(w.Quadtree)
+	if w.Quadtree != nil {
+		w.Quadtree.Clear()
+	}
+	w.Enemies.Reset()
+
+	w.LevelID = ""
+	w.Level = nil
+	w.Dynamic = nil
+	w.Exits = nil
+	w.Checkpoints = nil
+	w.PendingLevel = ""
+}
+
+// LoadHeadlessWorld builds a world with a sprite-less player and a sequential
+// enemy manager. level is either a registered level ID or a path to a map file.
+func LoadHeadlessWorld(level string) (*World, error) {
+	player, err := core.InitPlayer()
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWorld(&player, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := LevelByID(level); err == nil {
+		return w, w.EnterLevel(info)
+	}
+
+	data, err := core.LoadLevelFile(level)
+	if err != nil {
+		return nil, fmt.Errorf("load level: %w", err)
+	}
+	w.LoadLevel(level, data)
+	return w, nil
+}
+
+// Step advances the world by one fixed simulation step
+func (w *World) Step(input *core.InputState) {
+	dt := w.Clock.Step
+
+	if w.Recorder != nil {
+		w.Recorder.Record(*input)
+	}
+
+	// retrying goes through the input so a replay respawns on the same tick
+	if input.Respawn && w.GameOver() {
+		w.Respawn()
+	}
+
+	w.updatePlatforms(dt)
+
+	// Update enemies
+	w.Enemies.Update(w.Player, w.Quadtree, dt)
+
+	core.UpdatePlayer(w.Player, input, w.Quadtree, dt)
+
+	// enemy swings land once both sides have moved
+	w.Enemies.AttackPlayer(w.Player)
+
+	w.Player.UpdateAnimation(dt)
+
+	// player swings land once the animation frame of the step is known
+	w.checkPlayerHits()
+
+	// the animations of the step fire projectiles, then everything in flight moves
+	w.fireProjectiles()
+	w.updateProjectiles(dt)
+
+	// update camera position
+	w.Player.UpdateCamera(w.ViewWidth, w.ViewHeight, w.Width, w.Height)
+
+	w.checkExits()
+	w.checkCheckpoints()
+	w.checkDeath(dt)
+
+	input.ConsumeEdges()
+	w.Tick++
+}
+
+// checkExits flags the next level once the player touches an exit
+func (w *World) checkExits() {
+	if w.PendingLevel != "" || w.CampaignComplete {
+		return
+	}
+
+	bounds := w.Player.GetBounds()
+	for _, exit := range w.Exits {
+		area := core.AABB{X: exit.X, Y: exit.Y, Width: exit.Width, Height: exit.Height}
+		if !bounds.Intersects(area) {
+			continue
+		}
+
+		next := exit.Properties[ExitNextProperty]
+		if next == "" {
+			if info, err := LevelByID(w.LevelID); err == nil {
+				next = info.Next
+			}
+		}
+		if next == "" {
+			w.CampaignComplete = true
+		} else {
+			w.PendingLevel = next
+		}
+		return
+	}
+}
+
+// Run steps the world n times, asking inputFor for the input of every tick
+// and following level exits. A nil inputFor runs the world with no input at all.
+func (w *World) Run(n int, inputFor func(tick uint64) core.InputState) error {
+	var input core.InputState
+	for i := 0; i < n; i++ {
+		if inputFor != nil {
+			input = inputFor(w.Tick)
+		}
+		w.Step(&input)
+		if _, err := w.AdvanceLevel(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the enemy workers
+func (w *World) Close() {
+	if w.Enemies != nil {
+		w.Enemies.Shutdown()
+	}
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"player/internal/core"
+)
+
+var (
+	testEmpty = color.RGBA{255, 255, 255, 255}
+	testGrass = color.RGBA{0, 255, 0, 255}
+	testSpawn = color.RGBA{0, 0, 255, 255}
+)
+
+// testPalette knows grass and the spawn point
+func testPalette() *core.Palette {
+	return &core.Palette{
+		Empty:    map[color.RGBA]bool{testEmpty: true},
+		Tiles:    map[color.RGBA]core.TileType{testGrass: core.Grass},
+		Entities: map[color.RGBA]core.EntityKind{testSpawn: core.EntitySpawnPoint},
+	}
+}
+
+// flatLevel is a w by h tile level with a floor along the bottom row and the
+// spawn point a few tiles above it
+func flatLevel(t *testing.T, w, h int) *core.LevelData {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, testEmpty)
+		}
+	}
+	for x := 0; x < w; x++ {
+		img.Set(x, h-1, testGrass)
+	}
+	img.Set(2, h-4, testSpawn)
+
+	level, err := core.LoadLevel(img, testPalette())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return level
+}
+
+// newTestWorld is a world with a fresh player on a flat level
+func newTestWorld(t *testing.T) *World {
+	t.Helper()
+	player, err := core.InitPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWorld(&player, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Close)
+	w.LoadLevel("test", flatLevel(t, 20, 10))
+	return w
+}
+
+func holdRight(tick uint64) core.InputState {
+	return core.InputState{Direction: core.Direction{LeftRight: 1}}
+}
+
+func TestWorldPlayerLandsOnFloor(t *testing.T) {
+	w := newTestWorld(t)
+	const ticks = 2 * core.TickRate
+	if err := w.Run(ticks, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Tick != ticks {
+		t.Fatalf("Tick = %d, want %d", w.Tick, ticks)
+	}
+	if !w.Player.OnGround {
+		t.Fatal("player is not on the ground after falling for 2 seconds")
+	}
+	floor := w.Level[0].GetBounds().Y
+	if feet := w.Player.Pos.Y + w.Player.Height; math.Abs(feet-floor) > 1 {
+		t.Fatalf("player feet at %.2f, want the top of the floor at %.2f", feet, floor)
+	}
+	if w.PlayerDead() {
+		t.Fatal("player died standing on the floor")
+	}
+}
+
+func TestWorldPlayerWalksRight(t *testing.T) {
+	w := newTestWorld(t)
+	startX := w.Player.Pos.X
+	if err := w.Run(core.TickRate, holdRight); err != nil {
+		t.Fatal(err)
+	}
+	if w.Player.Pos.X <= startX {
+		t.Fatalf("player x = %.2f after holding right, started at %.2f", w.Player.Pos.X, startX)
+	}
+}
+
+func TestWorldStepIsDeterministic(t *testing.T) {
+	a, b := newTestWorld(t), newTestWorld(t)
+	for _, w := range []*World{a, b} {
+		if err := w.Run(3*core.TickRate, holdRight); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.Hash() != b.Hash() {
+		t.Fatalf("two runs of the same inputs ended in different states: %x != %x", a.Hash(), b.Hash())
+	}
+}
+
+// pitLevel has no floor, the player falls out of it after a second or two
+func pitLevel(t *testing.T) *core.LevelData {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, testEmpty)
+		}
+	}
+	img.Set(2, 2, testSpawn)
+
+	level, err := core.LoadLevel(img, testPalette())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return level
+}
+
+func TestWorldRespawnIsReplayed(t *testing.T) {
+	respawnTick := uint64(math.Ceil((RespawnDelay + 2) * core.TickRate))
+	inputFor := func(tick uint64) core.InputState {
+		return core.InputState{Respawn: tick == respawnTick}
+	}
+
+	w := newTestWorld(t)
+	w.LoadLevel("pit", pitLevel(t))
+	w.Recorder = NewRecorder(w)
+	if err := w.Run(int(respawnTick), inputFor); err != nil {
+		t.Fatal(err)
+	}
+	if !w.GameOver() {
+		t.Fatal("player did not die falling out of the level")
+	}
+	if err := w.Run(1, inputFor); err != nil {
+		t.Fatal(err)
+	}
+	if w.PlayerDead() {
+		t.Fatal("respawn input did not bring the player back")
+	}
+	rec := w.Recorder.Finish(w)
+
+	replay := newTestWorld(t)
+	replay.LoadLevel("pit", pitLevel(t))
+	if err := replay.Replay(rec); err != nil {
+		t.Fatal(err)
+	}
+	if replay.PlayerDead() {
+		t.Fatal("the replay did not respawn the player")
+	}
+}
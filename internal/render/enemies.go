@@ -0,0 +1,61 @@
+package render
+
+import (
+	"player/enemy"
+	"player/internal/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawEnemies draws every enemy of em from the enemy sprite sheet
+func DrawEnemies(screen, sheet *ebiten.Image, em *enemy.ParallelEnemyManager, camera core.Camera, alpha float64) {
+	for i := range em.EnemyManager {
+		enemies := em.EnemyManager[i].Enemies
+		for j := range enemies {
+			drawEnemy(screen, sheet, &enemies[j], em.Animations, camera, alpha)
+		}
+	}
+}
+
+// drawEnemy draws the enemy interpolated alpha of the way between the last two
+// simulation steps; camera is expected to be interpolated by the caller.
+func drawEnemy(screen, sheet *ebiten.Image, e *enemy.EnemyRuntime, animations map[int]enemy.Animation, camera core.Camera, alpha float64) {
+	bounds := e.GetBounds()
+	renderPos := core.Lerp(e.PrevPos, e.Pos, alpha)
+	bounds.X, bounds.Y = renderPos.X, renderPos.Y
+
+	// Look up animation for current state, fallback to idle
+	anim, ok := animations[e.State.Current]
+	if !ok {
+		anim = animations[enemy.StateIdle]
+	}
+	subImage := sheet.SubImage(anim.SpriteRect(e.CurrAnimFrame)).(*ebiten.Image)
+
+	op := &ebiten.DrawImageOptions{}
+	if r, g, b, ok := e.Statuses.Tint(); ok {
+		op.ColorScale.Scale(r, g, b, 1)
+	}
+
+	width, height := float64(anim.FrameWidth), float64(anim.FrameHeight)
+	if e.FlipX {
+		// Flip horizontally
+		op.GeoM.Scale(-e.Scale, e.Scale)
+		// Translate back because flipping moves the image to the left of the axis
+		op.GeoM.Translate(width*e.Scale, 0)
+	} else {
+		op.GeoM.Scale(e.Scale, e.Scale)
+	}
+
+	// Center horizontally on collision box
+	drawX := bounds.X + (bounds.Width-width*e.Scale)/2
+	// Align bottom to collision box bottom
+	drawY := bounds.Y + (bounds.Height - height*e.Scale)
+
+	// Apply camera offset
+	drawX -= camera.Pos.X
+	drawY -= camera.Pos.Y
+
+	op.GeoM.Translate(drawX, drawY)
+
+	screen.DrawImage(subImage, op)
+}
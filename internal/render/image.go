@@ -0,0 +1,15 @@
+// Package render draws the simulation with ebiten. The simulation packages
+// (core, enemy and game) never import it, so they build and run their tests
+// on machines with no display.
+package render
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// LoadImage loads a sprite sheet, tileset or background for drawing
+func LoadImage(path string) (*ebiten.Image, error) {
+	img, _, err := ebitenutil.NewImageFromFile(path)
+	return img, err
+}
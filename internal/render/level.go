@@ -0,0 +1,114 @@
+package render
+
+import (
+	"image"
+
+	"player/internal/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawParallaxBackground scrolls the background slower than the level as the
+// camera crosses it
+func DrawParallaxBackground(screen, background *ebiten.Image, camera core.Camera, screenWidth, screenHeight, levelWidth float64) {
+	bgW := background.Bounds().Dx()
+	bgH := background.Bounds().Dy()
+
+	parallaxFactor := 0.3
+
+	baseScale := screenHeight / float64(bgH)
+	scale := baseScale * (1 + parallaxFactor)
+
+	scaledW := float64(bgW) * scale
+
+	// Calculate offset based on camera position relative to total level width
+	maxCamX := levelWidth - screenWidth
+	if maxCamX < 1 {
+		maxCamX = 1
+	}
+
+	currentCamX := camera.Pos.X
+	if currentCamX < 0 {
+		currentCamX = 0
+	} else if currentCamX > maxCamX {
+		currentCamX = maxCamX
+	}
+
+	// Map camera progress (0-1) to background scroll
+	// We shift the background from 0 to -(scaledW - screenWidth)
+	progression := currentCamX / maxCamX
+	maxBgOffset := scaledW - screenWidth
+	bgOffset := progression * maxBgOffset
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(-bgOffset, 0)
+	screen.DrawImage(background, op)
+}
+
+// DrawLevel draws the platforms of quadtree the camera sees from the tileset,
+// moving ones interpolated alpha of the way between the last two simulation steps
+func DrawLevel(screen, tileset *ebiten.Image, quadtree *core.DynamicQuadtree, camera core.Camera, screenWidth, screenHeight, alpha float64) {
+	// Define the camera viewport
+	viewport := core.AABB{
+		X:      camera.Pos.X,
+		Y:      camera.Pos.Y,
+		Width:  screenWidth,
+		Height: screenHeight,
+	}
+
+	// Retrieve visible platforms from the Quadtree
+	visibleObjects := quadtree.Retrieve(viewport)
+
+	// Draw visible platforms
+	for _, obj := range visibleObjects {
+		if p, ok := obj.(*core.Platform); ok {
+			if p.IsSlope() {
+				drawSlope(screen, tileset, p, camera.Pos.X, camera.Pos.Y)
+				continue
+			}
+
+			// draw the tile image
+			op := &ebiten.DrawImageOptions{}
+			// Scale the tile image (PixelTileWidth/Height) to fit the platform size (LevelTileWidth/Height)
+			scaleX := float64(core.LevelTileWidth) / float64(core.PixelTileWidth)
+			scaleY := float64(core.LevelTileHeight) / float64(core.PixelTileHeight)
+			op.GeoM.Scale(scaleX, scaleY)
+
+			// translate is used to position the tile image on the screen
+			x, y := p.DrawPos(alpha)
+			op.GeoM.Translate(float64(x-camera.Pos.X), float64(y+p.DrawOffsetY-camera.Pos.Y))
+
+			// Draw the sub-image from the tileset using coordinates from TileInfo,
+			// once per tile for platforms wider than one
+			tile := tileset.SubImage(image.Rect(int(p.TileInfo.X), int(p.TileInfo.Y), int(p.TileInfo.X)+core.PixelTileWidth, int(p.TileInfo.Y)+core.PixelTileHeight)).(*ebiten.Image)
+			for ox := 0.0; ox < p.Width; ox += core.LevelTileWidth {
+				screen.DrawImage(tile, op)
+				op.GeoM.Translate(core.LevelTileWidth, 0)
+			}
+		}
+	}
+}
+
+// drawSlope draws the part of a slope's tile below its surface, cutting the
+// tileset image along the slope
+func drawSlope(screen, tileset *ebiten.Image, p *core.Platform, camX, camY float64) {
+	left, right := p.Shape.SurfaceHeights()
+	cutL, cutR := float32(1-left), float32(1-right) // share of the tile above the surface at each edge
+
+	x, y := float32(p.X-camX), float32(p.Y+p.DrawOffsetY-camY)
+	w, h := float32(core.LevelTileWidth), float32(core.LevelTileHeight)
+	sx, sy := float32(p.TileInfo.X), float32(p.TileInfo.Y)
+	sw, sh := float32(core.PixelTileWidth), float32(core.PixelTileHeight)
+
+	vertices := []ebiten.Vertex{
+		{DstX: x, DstY: y + h*cutL, SrcX: sx, SrcY: sy + sh*cutL},
+		{DstX: x + w, DstY: y + h*cutR, SrcX: sx + sw, SrcY: sy + sh*cutR},
+		{DstX: x + w, DstY: y + h, SrcX: sx + sw, SrcY: sy + sh},
+		{DstX: x, DstY: y + h, SrcX: sx, SrcY: sy + sh},
+	}
+	for i := range vertices {
+		vertices[i].ColorR, vertices[i].ColorG, vertices[i].ColorB, vertices[i].ColorA = 1, 1, 1, 1
+	}
+	screen.DrawTriangles(vertices, []uint16{0, 1, 2, 0, 2, 3}, tileset, nil)
+}
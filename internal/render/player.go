@@ -0,0 +1,54 @@
+package render
+
+import (
+	"player/internal/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawPlayer draws the player from its sprite sheet, interpolated alpha of the
+// way between the last two simulation steps
+func DrawPlayer(screen, sheet *ebiten.Image, player *core.PlayerRuntime, alpha float64) {
+	bounds := player.GetBounds()
+	renderPos := core.Lerp(player.PrevPos, player.Pos, alpha)
+	bounds.X, bounds.Y = renderPos.X, renderPos.Y
+	camera := player.ViewCamera(alpha)
+
+	anim := player.Animations[player.State.GetPlayerState()]
+	subImage := sheet.SubImage(anim.SpriteRect(player.CurrAnimFrame)).(*ebiten.Image)
+
+	op := &ebiten.DrawImageOptions{}
+	if r, g, b, ok := player.Statuses.Tint(); ok {
+		op.ColorScale.Scale(r, g, b, 1)
+	}
+	if player.PowerDeniedMs > 0 {
+		// not enough power for the attack
+		op.ColorScale.Scale(0.5, 0.5, 1, 1)
+	}
+
+	width, height := float64(anim.FrameWidth), float64(anim.FrameHeight)
+	if player.FlipX {
+		// Flip horizontally
+		op.GeoM.Scale(-player.Scale, player.Scale)
+		// Translate back because flipping moves the image to the left of the axis
+		op.GeoM.Translate(width*player.Scale, 0)
+	} else {
+		op.GeoM.Scale(player.Scale, player.Scale)
+	}
+
+	// Calculate draw position to center the sprite on the collision box
+	// Center horizontally: bounds.X + (bounds.Width - spriteWidth) / 2
+	drawX := bounds.X + (bounds.Width-width*player.Scale)/2
+
+	// Align bottom vertically: bounds.Y + (bounds.Height - spriteHeight)
+	drawY := bounds.Y + (bounds.Height - height*player.Scale)
+
+	// Apply Camera Offset
+	drawX -= camera.Pos.X
+	drawY -= camera.Pos.Y
+
+	// Move to the calculated position
+	op.GeoM.Translate(drawX, drawY)
+
+	screen.DrawImage(subImage, op)
+}
@@ -0,0 +1,17 @@
+package render
+
+import (
+	"player/internal/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// DrawProjectiles draws the projectiles interpolated alpha of the way between the last two simulation steps
+func DrawProjectiles(screen *ebiten.Image, pp *core.Projectiles, camera core.Camera, alpha float64) {
+	pp.Each(func(p *core.Projectile) {
+		k := p.Spec()
+		pos := core.Lerp(p.PrevPos, p.Pos, alpha)
+		vector.FillRect(screen, float32(pos.X-camera.Pos.X), float32(pos.Y-camera.Pos.Y), float32(k.Width), float32(k.Height), k.Color, false)
+	})
+}
@@ -36,10 +36,11 @@ func HandleInput(inputState *core.InputState) {
 	}
 
 	// ---------------- jump ----------------
-	inputState.JumpJustPressed = ebiten.IsKeyPressed(ebiten.KeySpace)
+	inputState.JumpJustPressed = inpututil.IsKeyJustPressed(ebiten.KeySpace)
+	inputState.JumpHeld = ebiten.IsKeyPressed(ebiten.KeySpace)
 
 	// ---------------- dash ----------------
-	inputState.DashJustPressed = ebiten.IsKeyPressed(ebiten.KeyShiftLeft)
+	inputState.DashJustPressed = inpututil.IsKeyJustPressed(ebiten.KeyE)
 
 	// ---------------- run ----------------
 	inputState.RunJustPressed = ebiten.IsKeyPressed(ebiten.KeyShiftLeft)
@@ -48,15 +49,18 @@ func HandleInput(inputState *core.InputState) {
 	inputState.SmugFace = ebiten.IsKeyPressed(ebiten.KeyF)
 
 	// ---------------- menu ----------------
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-		inputState.Menu = !inputState.Menu
-	}
+	inputState.Menu = inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+	inputState.Confirm = inpututil.IsKeyJustPressed(ebiten.KeyEnter)
+
+	// ---------------- save ----------------
+	inputState.QuickSave = inpututil.IsKeyJustPressed(ebiten.KeyF5)
+	inputState.QuickLoad = inpututil.IsKeyJustPressed(ebiten.KeyF9)
 
 	// ---------------- skills ----------------
 	inputState.Skills.WeakAttack = ebiten.IsKeyPressed(ebiten.KeyJ)
 	inputState.Skills.StrongAttack = ebiten.IsKeyPressed(ebiten.KeyI)
 	inputState.Skills.Defense = ebiten.IsKeyPressed(ebiten.KeyR)
-	inputState.Skills.UsePotion = ebiten.IsKeyPressed(ebiten.KeyQ)
+	inputState.Skills.UsePotion = inpututil.IsKeyJustPressed(ebiten.KeyQ)
 	inputState.Skills.SpecialAttack1 = ebiten.IsKeyPressed(ebiten.KeyK)
 	inputState.Skills.SpecialAttack2 = ebiten.IsKeyPressed(ebiten.KeyL)
 	inputState.Skills.SpecialAttack3 = ebiten.IsKeyPressed(ebiten.KeyU)